@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/timmbarton/layout/configloader"
 	"github.com/timmbarton/layout/executor"
@@ -19,6 +20,15 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = migrate(cfg, os.Args[2:])
+		if err != nil {
+			log.Println(err)
+		}
+
+		return
+	}
+
 	a, err := app.New(cfg)
 	if err != nil {
 		log.Println(err)
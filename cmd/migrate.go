@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/timmbarton/layout/components/postgresconn"
+
+	"backend/internal/config"
+	"backend/internal/migrations"
+)
+
+// migrate выполняет подкоманду migrate:
+//
+//	migrate up          - накатить все миграции
+//	migrate down [N]    - откатить N последних миграций (по умолчанию 1)
+func migrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | migrate down [steps]")
+	}
+
+	ctx := context.Background()
+
+	pg, err := postgresconn.New(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+
+	err = pg.Start(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = pg.Stop(ctx) }()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(ctx, pg.DB())
+		for _, m := range applied {
+			log.Printf("applied migration %d_%s", m.Version, m.Name)
+		}
+
+		return err
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New("steps must be a positive number")
+			}
+		}
+
+		reverted, err := migrations.Down(ctx, pg.DB(), steps)
+		for _, m := range reverted {
+			log.Printf("reverted migration %d_%s", m.Version, m.Name)
+		}
+
+		return err
+	default:
+		return errors.New("unknown migrate command: " + args[0])
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"backend/internal/config"
 	"backend/internal/delivery/http"
+	"backend/internal/migrations"
 	"backend/internal/repository"
 	"backend/internal/usecase"
 	"backend/pkg/coingecko"
@@ -22,13 +23,26 @@ func New(cfg config.Config) (executor.App, error) {
 		return nil, err
 	}
 
+	tgadsClient, err := tgads.New(cfg.TgAds)
+	if err != nil {
+		return nil, err
+	}
+
 	r := repository.New(pg.DB())
-	uc := usecase.New(cfg.UseCase, r, tgads.New(), coingecko.New(cfg.CoinGeckoApiKey))
+	uc := usecase.New(cfg.UseCase, r, tgadsClient, coingecko.New(cfg.CoinGeckoApiKey))
 	httpServer := http.New(cfg.HTTP, uc)
 
 	a.AddComponents(
 		tracingconn.New(cfg.Tracing),
 		pg,
+	)
+
+	// Миграции накатываются после старта подключения к Postgres и до запуска остальных компонентов
+	if cfg.MigrateOnStart {
+		a.AddComponents(migrations.NewComponent(pg))
+	}
+
+	a.AddComponents(
 		uc,
 		httpServer,
 	)
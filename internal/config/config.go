@@ -6,6 +6,7 @@ import (
 	"github.com/timmbarton/layout/components/tracingconn"
 
 	"backend/internal/usecase"
+	"backend/pkg/tgads"
 )
 
 type Config struct {
@@ -13,5 +14,7 @@ type Config struct {
 	Postgres        postgresconn.Config
 	Tracing         tracingconn.Config
 	UseCase         usecase.Config
+	TgAds           tgads.Config
 	CoinGeckoApiKey string `validate:"required"`
+	MigrateOnStart  bool
 }
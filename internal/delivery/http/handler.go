@@ -1,8 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/timmbarton/utils/types/dates"
 
 	"backend/internal/usecase"
 )
@@ -17,5 +26,169 @@ func (h *handler) bind(r fiber.Router) {
 	{
 		campaignsGroup.Get("/", h.campaignsGet)
 		campaignsGroup.Post("/", h.campaignsPost)
+		campaignsGroup.Post("/bulk", h.campaignsBulkPost)
+		campaignsGroup.Post("/refresh", h.campaignsRefreshPost)
+		campaignsGroup.Get("/:id", h.campaignGet)
+		campaignsGroup.Patch("/:id", h.campaignPatch)
+		campaignsGroup.Delete("/:id", h.campaignDelete)
+		campaignsGroup.Post("/:id/archive", h.campaignArchivePost)
+		campaignsGroup.Post("/:id/refresh", h.campaignRefreshPost)
+		campaignsGroup.Get("/:id/stats", h.campaignStatsGet)
+		campaignsGroup.Get("/:id/stats/hourly", h.campaignHourlyStatsGet)
+		campaignsGroup.Get("/:id/revisions", h.campaignRevisionsGet)
+		campaignsGroup.Post("/:id/tags", h.campaignTagsPost)
+		campaignsGroup.Delete("/:id/tags/:tag", h.campaignTagDelete)
+		campaignsGroup.Put("/:id/folder", h.campaignFolderPut)
+	}
+
+	r.Get("/tags", h.tagsGet)
+	r.Get("/folders", h.foldersGet)
+	r.Post("/folders", h.foldersPost)
+	r.Delete("/folders/:id", h.folderDelete)
+	r.Get("/jobs", h.jobsGet)
+	r.Get("/jobs/:id", h.jobGet)
+	r.Get("/health/scraper", h.scraperHealthGet)
+}
+
+// optionalString возвращает nil для пустой строки
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// parseOptionalBool разбирает bool, для пустой строки возвращает nil
+func parseOptionalBool(s string) (res *bool, err error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// parseOptionalId разбирает положительный числовой id, для пустой строки возвращает nil
+func parseOptionalId(s string) (res *int64, err error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, fmt.Errorf("id %d must be positive", id)
+	}
+
+	return &id, nil
+}
+
+// parseLimit разбирает размер страницы, для пустой строки возвращает 0 - значение по умолчанию
+func parseLimit(s string) (res int, err error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	res, err = strconv.Atoi(s)
+	if err != nil {
+		return 0, err
 	}
+
+	if res <= 0 {
+		return 0, fmt.Errorf("limit %d must be positive", res)
+	}
+
+	return res, nil
+}
+
+// Периоды выборки статистики в днях, если не передан from
+const (
+	defaultStatsPeriodDays       = 30
+	defaultHourlyStatsPeriodDays = 7
+)
+
+// parseDate разбирает дату в формате YYYY-MM-DD, для пустой строки возвращает def
+func parseDate(s string, def time.Time) (res dates.Date, err error) {
+	if s == "" {
+		return dates.Date(def), nil
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return res, err
+	}
+
+	return dates.Date(t), nil
+}
+
+// bulkFileField - поле multipart-формы с файлом ссылок
+const bulkFileField = "file"
+
+// parseBulkCampaigns разбирает пакет РК из JSON-массива, multipart-файла или тела text/plain, text/csv.
+// В текстовом формате каждая строка - ссылка и, через запятую, необязательное название
+func parseBulkCampaigns(c *fiber.Ctx) (res []*usecase.BulkCampaignItem, err error) {
+	switch {
+	case strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON):
+		err = c.BodyParser(&res)
+		if err != nil {
+			return res, err
+		}
+
+		return res, nil
+	case strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm):
+		fh, err := c.FormFile(bulkFileField)
+		if err != nil {
+			return res, err
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			return res, err
+		}
+		defer f.Close()
+
+		return parseBulkCampaignsText(f)
+	default:
+		return parseBulkCampaignsText(bytes.NewReader(c.Body()))
+	}
+}
+
+func parseBulkCampaignsText(r io.Reader) (res []*usecase.BulkCampaignItem, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return res, err
+	}
+
+	res = make([]*usecase.BulkCampaignItem, 0, len(records))
+
+	for i, record := range records {
+		link := strings.TrimSpace(record[0])
+
+		// Пропускаем пустые строки и заголовок CSV
+		if link == "" || (i == 0 && strings.EqualFold(link, "link")) {
+			continue
+		}
+
+		item := &usecase.BulkCampaignItem{Link: link}
+
+		if len(record) > 1 {
+			item.Name = strings.TrimSpace(record[1])
+		}
+
+		res = append(res, item)
+	}
+
+	return res, nil
 }
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"net/url"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/timmbarton/response"
 	"github.com/timmbarton/utils/tracing"
@@ -14,7 +17,38 @@ func (h *handler) campaignsGet(c *fiber.Ctx) error {
 	defer span.End()
 	c.SetUserContext(ctx)
 
-	res, err := h.uc.FetchCampaigns(ctx)
+	active, err := parseOptionalBool(c.Query("active"))
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	folderId, err := parseOptionalId(c.Query("folder"))
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	req := usecase.FetchCampaignsRequest{
+		Tag:      optionalString(c.Query("tag")),
+		FolderId: folderId,
+		Active:   active,
+		Query:    optionalString(c.Query("q")),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+		Limit:    limit,
+		Cursor:   c.Query("cursor"),
+	}
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	res, err := h.uc.FetchCampaigns(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -34,15 +68,411 @@ func (h *handler) campaignsPost(c *fiber.Ctx) error {
 		return errlist.ErrBadRequest
 	}
 
+	req.Wait = c.QueryBool("wait")
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	res, err := h.uc.CreateCampaign(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignsBulkPost(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	items, err := parseBulkCampaigns(c)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	req := usecase.CreateCampaignsBulkRequest{Items: items}
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	res, err := h.uc.CreateCampaignsBulk(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.GetCampaign(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignPatch(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	req := usecase.UpdateCampaignRequest{}
+
+	err := c.BodyParser(&req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	req.Id = c.Params("id")
+
+	if req.Name == nil && req.Notes == nil && req.Client == nil {
+		return errlist.ErrBadRequest
+	}
+
 	err = h.v.Struct(req)
 	if err != nil {
 		return errlist.ErrBadRequest
 	}
 
-	err = h.uc.CreateCampaign(ctx, req)
+	res, err := h.uc.UpdateCampaign(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignDelete(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	err := h.uc.DeleteCampaign(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return response.Ok(c)
+}
+
+func (h *handler) campaignArchivePost(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	err := h.uc.ArchiveCampaign(ctx, c.Params("id"))
 	if err != nil {
 		return err
 	}
 
 	return response.Ok(c)
 }
+
+func (h *handler) campaignStatsGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	req, err := h.parseStatsRequest(c, defaultStatsPeriodDays)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.uc.FetchStats(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignHourlyStatsGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	req, err := h.parseStatsRequest(c, defaultHourlyStatsPeriodDays)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.uc.FetchHourlyStats(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+// parseStatsRequest разбирает id РК и период from/to. Если from не передан, берутся periodDays дней до to
+func (h *handler) parseStatsRequest(c *fiber.Ctx, periodDays int) (req usecase.FetchStatsRequest, err error) {
+	to, err := parseDate(c.Query("to"), time.Now().UTC())
+	if err != nil {
+		return req, errlist.ErrBadRequest
+	}
+
+	from, err := parseDate(c.Query("from"), time.Time(to).AddDate(0, 0, -periodDays))
+	if err != nil {
+		return req, errlist.ErrBadRequest
+	}
+
+	if time.Time(from).After(time.Time(to)) {
+		return req, errlist.ErrBadRequest
+	}
+
+	req = usecase.FetchStatsRequest{
+		CampaignId: c.Params("id"),
+		From:       from,
+		To:         to,
+	}
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return req, errlist.ErrBadRequest
+	}
+
+	return req, nil
+}
+
+func (h *handler) campaignRevisionsGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.FetchCampaignRevisions(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignTagsPost(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	req := usecase.AddCampaignTagsRequest{}
+
+	err := c.BodyParser(&req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	req.CampaignId = c.Params("id")
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	err = h.uc.AddCampaignTags(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.Ok(c)
+}
+
+func (h *handler) campaignTagDelete(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	tag, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	err = h.uc.RemoveCampaignTag(ctx, c.Params("id"), tag)
+	if err != nil {
+		return err
+	}
+
+	return response.Ok(c)
+}
+
+func (h *handler) tagsGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.FetchTags(ctx)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignFolderPut(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	req := usecase.SetCampaignFolderRequest{}
+
+	err := c.BodyParser(&req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	req.CampaignId = c.Params("id")
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	err = h.uc.SetCampaignFolder(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.Ok(c)
+}
+
+func (h *handler) foldersGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.FetchFolders(ctx)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) foldersPost(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	req := usecase.CreateFolderRequest{}
+
+	err := c.BodyParser(&req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	res, err := h.uc.CreateFolder(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) folderDelete(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	id, err := parseOptionalId(c.Params("id"))
+	if err != nil || id == nil {
+		return errlist.ErrBadRequest
+	}
+
+	err = h.uc.DeleteFolder(ctx, *id)
+	if err != nil {
+		return err
+	}
+
+	return response.Ok(c)
+}
+
+func (h *handler) campaignsRefreshPost(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.StartRefresh(ctx, usecase.StartRefreshRequest{})
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) campaignRefreshPost(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.StartRefresh(ctx, usecase.StartRefreshRequest{CampaignId: c.Params("id")})
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) jobGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.GetJob(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) jobsGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	req := usecase.FetchJobsRequest{
+		Limit: limit,
+	}
+
+	err = h.v.Struct(req)
+	if err != nil {
+		return errlist.ErrBadRequest
+	}
+
+	res, err := h.uc.FetchJobs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
+
+func (h *handler) scraperHealthGet(c *fiber.Ctx) error {
+	ctx, span := tracing.NewSpan(c.UserContext())
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	res, err := h.uc.GetScraperHealth(ctx)
+	if err != nil {
+		return err
+	}
+
+	return response.OkWithData(c, res)
+}
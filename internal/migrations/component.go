@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBProvider - подключение к БД, которое открывается при старте своего компонента
+type DBProvider interface {
+	DB() *sqlx.DB
+}
+
+// Component накатывает миграции при старте приложения.
+// Добавляется в компоненты после подключения к Postgres, чтобы оно было уже открыто
+type Component struct {
+	pg DBProvider
+}
+
+func NewComponent(pg DBProvider) *Component {
+	return &Component{
+		pg: pg,
+	}
+}
+
+func (c *Component) Start(ctx context.Context) error {
+	applied, err := Up(ctx, c.pg.DB())
+	for _, m := range applied {
+		log.Printf("applied migration %d_%s", m.Version, m.Name)
+	}
+
+	return err
+}
+
+func (c *Component) Stop(_ context.Context) error { return nil }
+
+func (c *Component) GetName() string { return "Migrations" }
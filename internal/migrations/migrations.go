@@ -0,0 +1,282 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockId - ключ advisory lock, чтобы несколько реплик не накатывали миграции одновременно
+const lockId = 7_342_001
+
+const (
+	queryCreateMigrationsTable = `
+		CREATE TABLE IF NOT EXISTS public.schema_migrations
+		(
+			version    INT PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`
+	queryLock = `
+		SELECT pg_advisory_xact_lock($1::bigint)
+	`
+	queryMigrationApplied = `
+		SELECT EXISTS(SELECT 1 FROM public.schema_migrations WHERE version = $1::int)
+	`
+	queryLastMigration = `
+		SELECT version
+		FROM public.schema_migrations
+		ORDER BY version DESC
+		LIMIT 1
+	`
+	queryCreateMigration = `
+		INSERT INTO public.schema_migrations(version, name)
+		VALUES ($1::int, $2::text)
+	`
+	queryDeleteMigration = `
+		DELETE FROM public.schema_migrations
+		WHERE version = $1::int
+	`
+)
+
+var fileNameRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration описывает одну версию схемы БД
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load возвращает встроенные в бинарник миграции, отсортированные по версии
+func Load() (res []*Migration, err error) {
+	dir, err := fs.Sub(files, "sql")
+	if err != nil {
+		return res, err
+	}
+
+	return load(dir)
+}
+
+// load читает миграции из корня dir
+func load(dir fs.FS) (res []*Migration, err error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return res, err
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		matches := fileNameRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return res, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return res, err
+		}
+
+		body, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			return res, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != matches[2] {
+			return res, fmt.Errorf("migration %d has different names: %s, %s", version, m.Name, matches[2])
+		}
+
+		script := &m.Down
+		if matches[3] == "up" {
+			script = &m.Up
+		}
+
+		// Например, 1_init.up.sql и 0001_init.up.sql
+		if *script != "" {
+			return res, fmt.Errorf("migration %d has several %s scripts", version, matches[3])
+		}
+
+		*script = string(body)
+	}
+
+	res = make([]*Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return res, fmt.Errorf("migration %d has no up script", m.Version)
+		}
+
+		res = append(res, m)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+
+	return res, nil
+}
+
+// Up накатывает все ещё не применённые миграции. Каждая миграция выполняется в отдельной транзакции
+func Up(ctx context.Context, db *sqlx.DB) (applied []*Migration, err error) {
+	migrations, err := Load()
+	if err != nil {
+		return applied, err
+	}
+
+	err = createMigrationsTable(ctx, db)
+	if err != nil {
+		return applied, err
+	}
+
+	for _, m := range migrations {
+		ok := false
+
+		err = inTx(ctx, db, func(tx *sqlx.Tx) error {
+			exists := false
+
+			err := tx.GetContext(ctx, &exists, queryMigrationApplied, m.Version)
+			if err != nil || exists {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, m.Up)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+
+			_, err = tx.ExecContext(ctx, queryCreateMigration, m.Version, m.Name)
+			if err != nil {
+				return err
+			}
+
+			ok = true
+
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+
+		if ok {
+			applied = append(applied, m)
+		}
+	}
+
+	return applied, nil
+}
+
+// Down откатывает steps последних применённых миграций
+func Down(ctx context.Context, db *sqlx.DB, steps int) (reverted []*Migration, err error) {
+	migrations, err := Load()
+	if err != nil {
+		return reverted, err
+	}
+
+	byVersion := make(map[int]*Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	err = createMigrationsTable(ctx, db)
+	if err != nil {
+		return reverted, err
+	}
+
+	for range steps {
+		m := (*Migration)(nil)
+
+		err = inTx(ctx, db, func(tx *sqlx.Tx) error {
+			version := 0
+
+			err := tx.GetContext(ctx, &version, queryLastMigration)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			ok := false
+
+			m, ok = byVersion[version]
+			if !ok {
+				return fmt.Errorf("migration %d is applied but unknown", version)
+			}
+
+			if m.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+			}
+
+			_, err = tx.ExecContext(ctx, m.Down)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+
+			_, err = tx.ExecContext(ctx, queryDeleteMigration, m.Version)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		})
+		if err != nil {
+			return reverted, err
+		}
+
+		if m == nil {
+			break
+		}
+
+		reverted = append(reverted, m)
+	}
+
+	return reverted, nil
+}
+
+// createMigrationsTable создаёт таблицу версий под advisory lock:
+// CREATE TABLE IF NOT EXISTS не защищён от одновременного запуска реплик
+func createMigrationsTable(ctx context.Context, db *sqlx.DB) error {
+	return inTx(ctx, db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, queryCreateMigrationsTable)
+		return err
+	})
+}
+
+func inTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, queryLock, lockId)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
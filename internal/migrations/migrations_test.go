@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(body string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(body)}
+	}
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int
+		wantErr  bool
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"0010_ten.up.sql":   file("SELECT 10"),
+				"0002_two.up.sql":   file("SELECT 2"),
+				"0002_two.down.sql": file("SELECT -2"),
+				"0001_one.up.sql":   file("SELECT 1"),
+			},
+			versions: []int{1, 2, 10},
+		},
+		{
+			name: "duplicate version with different names",
+			files: fstest.MapFS{
+				"0001_one.up.sql":   file("SELECT 1"),
+				"0001_other.up.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate version with different padding",
+			files: fstest.MapFS{
+				"0001_one.up.sql": file("SELECT 1"),
+				"1_one.up.sql":    file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no up script",
+			files: fstest.MapFS{
+				"0001_one.down.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed name",
+			files: fstest.MapFS{
+				"0001_one.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no version",
+			files: fstest.MapFS{
+				"one.up.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := load(tt.files)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("load() returned %d migrations, want error", len(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.versions) {
+				t.Fatalf("load() returned %d migrations, want %d", len(got), len(tt.versions))
+			}
+
+			for i, version := range tt.versions {
+				if got[i].Version != version {
+					t.Errorf("load()[%d].Version = %d, want %d", i, got[i].Version, version)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s, want version %d", m.Version, m.Name, i+1)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}
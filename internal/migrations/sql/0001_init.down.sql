@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tgads.rates;
+DROP TABLE IF EXISTS tgads.stats;
+DROP TABLE IF EXISTS tgads.campaigns;
@@ -0,0 +1,32 @@
+CREATE SCHEMA IF NOT EXISTS tgads;
+
+CREATE TABLE IF NOT EXISTS tgads.campaigns
+(
+    id              TEXT PRIMARY KEY,
+    name            TEXT        NOT NULL DEFAULT '',
+    stats_csv_link  TEXT        NOT NULL DEFAULT '',
+    budget_csv_link TEXT        NOT NULL DEFAULT '',
+    text            TEXT        NOT NULL DEFAULT '',
+    button_text     TEXT        NOT NULL DEFAULT '',
+    link            TEXT        NOT NULL DEFAULT '',
+    active          BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS tgads.stats
+(
+    campaign_id TEXT    NOT NULL,
+    "date"      DATE    NOT NULL,
+    views       INT     NOT NULL DEFAULT 0,
+    clicks      INT     NOT NULL DEFAULT 0,
+    actions     INT     NOT NULL DEFAULT 0,
+    spend       DECIMAL NOT NULL DEFAULT 0,
+    cpm         DECIMAL NOT NULL DEFAULT 0,
+    PRIMARY KEY (campaign_id, "date")
+);
+
+CREATE TABLE IF NOT EXISTS tgads.rates
+(
+    "date" DATE PRIMARY KEY,
+    rate   DECIMAL NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS tgads.campaign_revisions;
@@ -0,0 +1,16 @@
+CREATE TABLE tgads.campaign_revisions
+(
+    id          BIGSERIAL PRIMARY KEY,
+    campaign_id TEXT        NOT NULL,
+    text        TEXT        NOT NULL,
+    button_text TEXT        NOT NULL,
+    link        TEXT        NOT NULL,
+    active      BOOLEAN     NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX campaign_revisions_campaign_id_idx ON tgads.campaign_revisions (campaign_id, id);
+
+INSERT INTO tgads.campaign_revisions(campaign_id, text, button_text, link, active, created_at)
+SELECT id, text, button_text, link, active, created_at
+FROM tgads.campaigns;
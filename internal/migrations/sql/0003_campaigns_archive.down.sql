@@ -0,0 +1,20 @@
+ALTER TABLE tgads.campaign_revisions
+    DROP CONSTRAINT IF EXISTS campaign_revisions_campaign_id_fkey;
+
+ALTER TABLE tgads.stats
+    DROP CONSTRAINT IF EXISTS stats_campaign_id_fkey;
+
+INSERT INTO tgads.stats
+SELECT *
+FROM tgads.stats_orphans;
+
+INSERT INTO tgads.campaign_revisions
+SELECT *
+FROM tgads.campaign_revisions_orphans;
+
+DROP TABLE IF EXISTS tgads.campaign_revisions_orphans;
+
+DROP TABLE IF EXISTS tgads.stats_orphans;
+
+ALTER TABLE tgads.campaigns
+    DROP COLUMN IF EXISTS archived_at;
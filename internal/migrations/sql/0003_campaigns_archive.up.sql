@@ -0,0 +1,36 @@
+ALTER TABLE tgads.campaigns
+    ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Строки удалённых РК не дают добавить внешние ключи. Они переносятся в отдельные таблицы,
+-- откуда их можно вернуть вручную или откатом миграции
+CREATE TABLE tgads.stats_orphans (LIKE tgads.stats);
+
+CREATE TABLE tgads.campaign_revisions_orphans (LIKE tgads.campaign_revisions);
+
+WITH moved AS (
+    DELETE
+        FROM tgads.stats
+            WHERE campaign_id NOT IN (SELECT id FROM tgads.campaigns)
+            RETURNING *)
+INSERT
+INTO tgads.stats_orphans
+SELECT *
+FROM moved;
+
+WITH moved AS (
+    DELETE
+        FROM tgads.campaign_revisions
+            WHERE campaign_id NOT IN (SELECT id FROM tgads.campaigns)
+            RETURNING *)
+INSERT
+INTO tgads.campaign_revisions_orphans
+SELECT *
+FROM moved;
+
+ALTER TABLE tgads.stats
+    ADD CONSTRAINT stats_campaign_id_fkey
+        FOREIGN KEY (campaign_id) REFERENCES tgads.campaigns (id) ON DELETE CASCADE;
+
+ALTER TABLE tgads.campaign_revisions
+    ADD CONSTRAINT campaign_revisions_campaign_id_fkey
+        FOREIGN KEY (campaign_id) REFERENCES tgads.campaigns (id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE tgads.campaigns
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS client;
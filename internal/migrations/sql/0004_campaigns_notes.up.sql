@@ -0,0 +1,3 @@
+ALTER TABLE tgads.campaigns
+    ADD COLUMN notes  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN client TEXT NOT NULL DEFAULT '';
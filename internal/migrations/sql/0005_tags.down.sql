@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tgads.campaign_tags;
+DROP TABLE IF EXISTS tgads.tags;
@@ -0,0 +1,15 @@
+CREATE TABLE tgads.tags
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE tgads.campaign_tags
+(
+    campaign_id TEXT   NOT NULL REFERENCES tgads.campaigns (id) ON DELETE CASCADE,
+    tag_id      BIGINT NOT NULL REFERENCES tgads.tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_id, tag_id)
+);
+
+CREATE INDEX campaign_tags_tag_id_idx ON tgads.campaign_tags (tag_id);
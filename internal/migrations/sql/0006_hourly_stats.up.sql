@@ -0,0 +1,11 @@
+CREATE TABLE tgads.hourly_stats
+(
+    campaign_id TEXT        NOT NULL REFERENCES tgads.campaigns (id) ON DELETE CASCADE,
+    datetime    TIMESTAMPTZ NOT NULL,
+    views       INT         NOT NULL DEFAULT 0,
+    clicks      INT         NOT NULL DEFAULT 0,
+    actions     INT         NOT NULL DEFAULT 0,
+    spend       DECIMAL     NOT NULL DEFAULT 0,
+    cpm         DECIMAL     NOT NULL DEFAULT 0,
+    PRIMARY KEY (campaign_id, datetime)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tgads.campaign_refresh_errors;
+DROP TABLE IF EXISTS tgads.job_runs;
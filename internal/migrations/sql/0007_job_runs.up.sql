@@ -0,0 +1,24 @@
+CREATE TABLE tgads.job_runs
+(
+    id          UUID PRIMARY KEY,
+    type        TEXT        NOT NULL,
+    status      TEXT        NOT NULL,
+    error       TEXT        NOT NULL DEFAULT '',
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    total       INT         NOT NULL DEFAULT 0,
+    processed   INT         NOT NULL DEFAULT 0,
+    succeeded   INT         NOT NULL DEFAULT 0,
+    failed      INT         NOT NULL DEFAULT 0
+);
+
+CREATE INDEX job_runs_started_at_idx ON tgads.job_runs (started_at DESC);
+
+CREATE TABLE tgads.campaign_refresh_errors
+(
+    campaign_id          TEXT PRIMARY KEY REFERENCES tgads.campaigns (id) ON DELETE CASCADE,
+    last_error           TEXT,
+    last_error_at        TIMESTAMPTZ,
+    last_success_at      TIMESTAMPTZ,
+    consecutive_failures INT NOT NULL DEFAULT 0
+);
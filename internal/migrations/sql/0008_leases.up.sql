@@ -0,0 +1,6 @@
+CREATE TABLE tgads.leases
+(
+    name       TEXT PRIMARY KEY,
+    holder     TEXT        NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
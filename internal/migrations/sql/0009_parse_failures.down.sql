@@ -0,0 +1 @@
+DROP TABLE IF EXISTS tgads.parse_failures;
@@ -0,0 +1,10 @@
+CREATE TABLE tgads.parse_failures
+(
+    id          BIGSERIAL PRIMARY KEY,
+    campaign_id TEXT        NOT NULL,
+    reason      TEXT        NOT NULL,
+    page        BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX parse_failures_campaign_id_created_at_idx ON tgads.parse_failures (campaign_id, created_at DESC);
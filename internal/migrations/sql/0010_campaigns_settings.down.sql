@@ -0,0 +1,9 @@
+ALTER TABLE tgads.campaigns
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS cpm_bid,
+    DROP COLUMN IF EXISTS budget,
+    DROP COLUMN IF EXISTS daily_budget,
+    DROP COLUMN IF EXISTS channels,
+    DROP COLUMN IF EXISTS languages,
+    DROP COLUMN IF EXISTS topics,
+    DROP COLUMN IF EXISTS ad_created_at;
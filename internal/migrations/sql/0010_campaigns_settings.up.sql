@@ -0,0 +1,9 @@
+ALTER TABLE tgads.campaigns
+    ADD COLUMN title         TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN cpm_bid       DECIMAL,
+    ADD COLUMN budget        DECIMAL,
+    ADD COLUMN daily_budget  DECIMAL,
+    ADD COLUMN channels      TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN languages     TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN topics        TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN ad_created_at TIMESTAMPTZ;
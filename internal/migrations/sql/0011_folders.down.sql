@@ -0,0 +1,3 @@
+ALTER TABLE tgads.campaigns
+    DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS tgads.folders;
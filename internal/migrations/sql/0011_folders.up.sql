@@ -0,0 +1,11 @@
+CREATE TABLE tgads.folders
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tgads.campaigns
+    ADD COLUMN folder_id BIGINT REFERENCES tgads.folders (id) ON DELETE SET NULL;
+
+CREATE INDEX campaigns_folder_id_idx ON tgads.campaigns (folder_id);
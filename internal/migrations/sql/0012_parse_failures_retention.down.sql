@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tgads.parse_failures_created_at_idx;
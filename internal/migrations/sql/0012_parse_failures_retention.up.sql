@@ -0,0 +1 @@
+CREATE INDEX parse_failures_created_at_idx ON tgads.parse_failures (created_at);
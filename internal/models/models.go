@@ -3,21 +3,129 @@ package models
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/timmbarton/utils/types/dates"
 )
 
 // Campaign описывает информацию о добавленной РК
 type Campaign struct {
-	Id            string    `json:"id" db:"id"`
-	Name          string    `json:"name" db:"name"`
-	StatsCSVLink  string    `json:"stats_csv_link" db:"stats_csv_link"`
-	BudgetCSVLink string    `json:"budget_csv_link" db:"budget_csv_link"`
-	Text          string    `json:"text" db:"text"`
-	ButtonText    string    `json:"button_text" db:"button_text"`
-	Link          string    `json:"link" db:"link"`
-	Active        bool      `json:"active" db:"active"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	Id            string `json:"id" db:"id"`
+	Name          string `json:"name" db:"name"`
+	Notes         string `json:"notes" db:"notes"`
+	Client        string `json:"client" db:"client"`
+	StatsCSVLink  string `json:"stats_csv_link" db:"stats_csv_link"`
+	BudgetCSVLink string `json:"budget_csv_link" db:"budget_csv_link"`
+	Text          string `json:"text" db:"text"`
+	ButtonText    string `json:"button_text" db:"button_text"`
+	Link          string `json:"link" db:"link"`
+	Active        bool   `json:"active" db:"active"`
+	// Настройки РК со страницы статистики
+	Title       string           `json:"title" db:"title"`
+	CPMBid      *decimal.Decimal `json:"cpm_bid" db:"cpm_bid"`
+	Budget      *decimal.Decimal `json:"budget" db:"budget"`
+	DailyBudget *decimal.Decimal `json:"daily_budget" db:"daily_budget"`
+	Channels    pq.StringArray   `json:"channels" db:"channels"`
+	Languages   pq.StringArray   `json:"languages" db:"languages"`
+	Topics      pq.StringArray   `json:"topics" db:"topics"`
+	AdCreatedAt *time.Time       `json:"ad_created_at" db:"ad_created_at"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	ArchivedAt  *time.Time       `json:"archived_at" db:"archived_at"`
+	FolderId    *int64           `json:"folder_id" db:"folder_id"`
+	Tags        pq.StringArray   `json:"tags" db:"tags"`
+}
+
+// CampaignsFilter описывает фильтры списка РК. nil-поля не применяются
+type CampaignsFilter struct {
+	Tag      *string
+	FolderId *int64
+	Active   *bool
+	Query    *string // подстрока названия или текста объявления
+}
+
+// CampaignListItem описывает РК в списке вместе с агрегатами статистики
+type CampaignListItem struct {
+	Campaign
+	TotalSpend decimal.Decimal `json:"total_spend" db:"total_spend"`
+	Views7d    int             `json:"views_7d" db:"views_7d"`
+	// Последняя ошибка обновления статистики и количество неудачных обновлений подряд
+	RefreshError    *string    `json:"refresh_error" db:"refresh_error"`
+	RefreshErrorAt  *time.Time `json:"refresh_error_at" db:"refresh_error_at"`
+	RefreshFailures int        `json:"refresh_failures" db:"refresh_failures"`
+}
+
+// Поля сортировки списка РК
+const (
+	CampaignsSortCreatedAt  = "created_at"
+	CampaignsSortName       = "name"
+	CampaignsSortTotalSpend = "total_spend"
+	CampaignsSortViews7d    = "views_7d"
+)
+
+// CampaignsCursor указывает на последнюю РК предыдущей страницы
+type CampaignsCursor struct {
+	Value string // значение поля сортировки
+	Id    string
+}
+
+// CampaignsPagination описывает сортировку и страницу списка РК
+type CampaignsPagination struct {
+	Sort  string
+	Desc  bool
+	Limit int
+	After *CampaignsCursor
+}
+
+// CampaignsPage описывает страницу списка РК
+type CampaignsPage struct {
+	Items      []*CampaignListItem `json:"items"`
+	NextCursor *string             `json:"next_cursor"`
+}
+
+// Tag описывает тег, которым можно пометить РК
+type Tag struct {
+	Id             int64  `json:"id" db:"id"`
+	Name           string `json:"name" db:"name"`
+	CampaignsCount int    `json:"campaigns_count" db:"campaigns_count"`
+}
+
+// Folder - папка РК. РК лежит не больше чем в одной папке, в отличие от тегов
+type Folder struct {
+	Id             int64  `json:"id" db:"id"`
+	Name           string `json:"name" db:"name"`
+	CampaignsCount int    `json:"campaigns_count" db:"campaigns_count"`
+}
+
+// CampaignTotals описывает суммарную статистику РК за всё время
+type CampaignTotals struct {
+	Views      int             `json:"views" db:"views"`
+	Clicks     int             `json:"clicks" db:"clicks"`
+	Actions    int             `json:"actions" db:"actions"`
+	Spend      decimal.Decimal `json:"spend" db:"spend"`
+	Cpm        decimal.Decimal `json:"cpm" db:"-"`
+	Ctr        decimal.Decimal `json:"ctr" db:"-"`
+	FirstDate  *dates.Date     `json:"first_date" db:"first_date"`
+	LastDate   *dates.Date     `json:"last_date" db:"last_date"`
+	DaysActive int             `json:"days_active" db:"days_active"`
+}
+
+// CampaignDetails описывает РК вместе с суммарной статистикой
+type CampaignDetails struct {
+	Campaign
+	Totals CampaignTotals `json:"totals"`
+	// RemainingBudget - бюджет РК за вычетом потраченного, если бюджет известен
+	RemainingBudget *decimal.Decimal `json:"remaining_budget"`
+}
+
+// CampaignRevision описывает версию объявления РК
+type CampaignRevision struct {
+	Id         int64     `json:"id" db:"id"`
+	CampaignId string    `json:"campaign_id" db:"campaign_id"`
+	Text       string    `json:"text" db:"text"`
+	ButtonText string    `json:"button_text" db:"button_text"`
+	Link       string    `json:"link" db:"link"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
 // Stats описывает статистику по РК за определённую дату
@@ -31,8 +139,87 @@ type Stats struct {
 	Cpm        decimal.Decimal `json:"cpm" db:"cpm"`
 }
 
+// HourlyStats описывает статистику по РК за определённый час
+type HourlyStats struct {
+	CampaignId string          `json:"campaign_id" db:"campaign_id"`
+	Datetime   time.Time       `json:"datetime" db:"datetime"`
+	Views      int             `json:"views" db:"views"`
+	Clicks     int             `json:"clicks" db:"clicks"`
+	Actions    int             `json:"actions" db:"actions"`
+	Spend      decimal.Decimal `json:"spend" db:"spend"`
+	Cpm        decimal.Decimal `json:"cpm" db:"cpm"`
+}
+
+// StatsUSD описывает статистику по РК за дату с пересчётом расходов в USD.
+// Если курса TON к USD за дату нет, поля в USD пустые, а RateMissing = true
+type StatsUSD struct {
+	Stats
+	Rate        *decimal.Decimal `json:"rate"`
+	SpendUsd    *decimal.Decimal `json:"spend_usd"`
+	CpmUsd      *decimal.Decimal `json:"cpm_usd"`
+	RateMissing bool             `json:"rate_missing"`
+}
+
 // Rate описывает курс TON к USD
 type Rate struct {
 	Date dates.Date      `json:"date" db:"date"`
 	Rate decimal.Decimal `json:"rate" db:"rate"`
 }
+
+// Типы задач
+const (
+	JobTypeRefreshStats    = "refresh_stats"
+	JobTypeRefreshCampaign = "refresh_campaign"
+	JobTypeImportStats     = "import_stats"
+	JobTypeLoadRates       = "load_rates"
+)
+
+// Статусы задач
+const (
+	JobStatusRunning     = "running"
+	JobStatusFinished    = "finished"
+	JobStatusFailed      = "failed"
+	JobStatusInterrupted = "interrupted"
+)
+
+// Job описывает задачу обновления статистики и её прогресс
+type Job struct {
+	Id         string     `json:"id" db:"id"`
+	Type       string     `json:"type" db:"type"`
+	Status     string     `json:"status" db:"status"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+	Total      int        `json:"total" db:"total"`
+	Processed  int        `json:"processed" db:"processed"`
+	Succeeded  int        `json:"succeeded" db:"succeeded"`
+	Failed     int        `json:"failed" db:"failed"`
+	// Results - результаты по РК. Хранятся только в памяти запустившего задачу процесса
+	Results []*JobCampaignResult `json:"results,omitempty" db:"-"`
+}
+
+// JobCampaignResult описывает результат обновления одной РК в рамках задачи
+type JobCampaignResult struct {
+	CampaignId string `json:"campaign_id"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ParseFailure - исходная страница РК, которую не удалось разобрать
+type ParseFailure struct {
+	CampaignId string
+	Reason     string
+	Page       []byte
+}
+
+// ScraperHealth описывает долю неразобранных страниц среди последних загрузок.
+// Healthy = false, когда доля превысила порог: скорее всего, Telegram поменял вёрстку.
+// Считается отдельно на каждой реплике. Пока Samples меньше минимума, Healthy = true
+type ScraperHealth struct {
+	Healthy     bool       `json:"healthy"`
+	FailureRate float64    `json:"failure_rate"`
+	Threshold   float64    `json:"threshold"`
+	Failures    int        `json:"failures"`
+	Samples     int        `json:"samples"`
+	ChangedAt   *time.Time `json:"changed_at"`
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/timmbarton/utils/tracing"
@@ -9,15 +12,19 @@ import (
 	"backend/internal/models"
 )
 
+// likeEscaper экранирует спецсимволы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type campaignsRepository struct {
 	pg *sqlx.DB
 }
 
-func (r *campaignsRepository) Create(ctx context.Context, c models.Campaign) error {
+// Create добавляет РК. created = false, если РК с таким id уже есть: она не меняется
+func (r *campaignsRepository) Create(ctx context.Context, c models.Campaign) (created bool, err error) {
 	ctx, span := tracing.NewSpan(ctx)
 	defer span.End()
 
-	_, err := r.pg.ExecContext(
+	res, err := r.pg.ExecContext(
 		ctx,
 		queryCreateCampaign,
 		c.Id,
@@ -28,6 +35,50 @@ func (r *campaignsRepository) Create(ctx context.Context, c models.Campaign) err
 		c.ButtonText,
 		c.Link,
 		c.Active,
+		c.Title,
+		c.CPMBid,
+		c.Budget,
+		c.DailyBudget,
+		c.Channels,
+		c.Languages,
+		c.Topics,
+		c.AdCreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// Update обновляет данные РК, полученные со страницы статистики. Название не меняется
+func (r *campaignsRepository) Update(ctx context.Context, c models.Campaign) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(
+		ctx,
+		queryUpdateCampaign,
+		c.Id,
+		c.StatsCSVLink,
+		c.BudgetCSVLink,
+		c.Text,
+		c.ButtonText,
+		c.Link,
+		c.Active,
+		c.Title,
+		c.CPMBid,
+		c.Budget,
+		c.DailyBudget,
+		c.Channels,
+		c.Languages,
+		c.Topics,
+		c.AdCreatedAt,
 	)
 	if err != nil {
 		return err
@@ -36,16 +87,140 @@ func (r *campaignsRepository) Create(ctx context.Context, c models.Campaign) err
 	return nil
 }
 
-func (r *campaignsRepository) Fetch(ctx context.Context) (res []*models.Campaign, err error) {
+// UpdateInfo обновляет заданные пользователем поля РК. nil-поля не меняются.
+// Если РК не найдена, возвращает sql.ErrNoRows
+func (r *campaignsRepository) UpdateInfo(ctx context.Context, id string, name, notes, client *string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err := r.pg.ExecContext(ctx, queryUpdateCampaignInfo, id, name, notes, client)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// campaignsSortTypes - допустимые поля сортировки списка РК и их типы
+var campaignsSortTypes = map[string]string{
+	models.CampaignsSortCreatedAt:  "timestamptz",
+	models.CampaignsSortName:       "text",
+	models.CampaignsSortTotalSpend: "decimal",
+	models.CampaignsSortViews7d:    "bigint",
+}
+
+func (r *campaignsRepository) Fetch(
+	ctx context.Context,
+	f models.CampaignsFilter,
+	p models.CampaignsPagination,
+) (res []*models.CampaignListItem, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.CampaignListItem, 0)
+
+	sortType, ok := campaignsSortTypes[p.Sort]
+	if !ok {
+		return res, fmt.Errorf("unknown sort field: %s", p.Sort)
+	}
+
+	cmp, direction := ">", "ASC"
+	if p.Desc {
+		cmp, direction = "<", "DESC"
+	}
+
+	query := (*string)(nil)
+	if f.Query != nil {
+		escaped := likeEscaper.Replace(*f.Query)
+		query = &escaped
+	}
+
+	afterValue, afterId := (*string)(nil), (*string)(nil)
+	if p.After != nil {
+		afterValue, afterId = &p.After.Value, &p.After.Id
+	}
+
+	err = r.pg.SelectContext(
+		ctx,
+		&res,
+		fmt.Sprintf(queryFetchCampaigns, p.Sort, sortType, cmp, direction),
+		f.Tag,
+		f.Active,
+		query,
+		afterValue,
+		afterId,
+		p.Limit,
+		f.FolderId,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *campaignsRepository) Get(ctx context.Context, id string) (res models.Campaign, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err = r.pg.GetContext(ctx, &res, queryGetCampaign, id)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// FetchNotArchived возвращает РК, статистику которых нужно обновлять
+func (r *campaignsRepository) FetchNotArchived(ctx context.Context) (res []*models.Campaign, err error) {
 	ctx, span := tracing.NewSpan(ctx)
 	defer span.End()
 
 	res = make([]*models.Campaign, 0)
 
-	err = r.pg.SelectContext(ctx, &res, queryFetchCampaigns)
+	err = r.pg.SelectContext(ctx, &res, queryFetchNotArchivedCampaigns)
 	if err != nil {
 		return res, err
 	}
 
 	return res, nil
 }
+
+// Archive помечает РК архивной. Если РК не найдена, возвращает sql.ErrNoRows
+func (r *campaignsRepository) Archive(ctx context.Context, id string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err := r.pg.ExecContext(ctx, queryArchiveCampaign, id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// Delete удаляет РК вместе со статистикой и сохранёнными неразобранными страницами. Если РК не найдена, возвращает sql.ErrNoRows
+func (r *campaignsRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err := r.pg.ExecContext(ctx, queryDeleteCampaign, id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
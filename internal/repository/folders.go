@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+)
+
+type foldersRepository struct {
+	pg *sqlx.DB
+}
+
+// Create создаёт папку. Если папка с таким названием уже есть, возвращает её
+func (r *foldersRepository) Create(ctx context.Context, name string) (res models.Folder, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err = r.pg.GetContext(ctx, &res, queryCreateFolder, name)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *foldersRepository) Get(ctx context.Context, id int64) (res models.Folder, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err = r.pg.GetContext(ctx, &res, queryGetFolder, id)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *foldersRepository) Fetch(ctx context.Context) (res []*models.Folder, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.Folder, 0)
+
+	err = r.pg.SelectContext(ctx, &res, queryFetchFolders)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// Delete удаляет папку. РК из неё остаются без папки. Если папка не найдена, возвращает sql.ErrNoRows
+func (r *foldersRepository) Delete(ctx context.Context, id int64) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err := r.pg.ExecContext(ctx, queryDeleteFolder, id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// SetCampaign перекладывает РК в папку, nil - убирает из папки. Если РК не найдена, возвращает sql.ErrNoRows
+func (r *foldersRepository) SetCampaign(ctx context.Context, campaignId string, folderId *int64) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err := r.pg.ExecContext(ctx, querySetCampaignFolder, campaignId, folderId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+)
+
+type jobsRepository struct {
+	pg *sqlx.DB
+}
+
+func (r *jobsRepository) Create(ctx context.Context, j models.Job) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(ctx, queryCreateJobRun, j.Id, j.Type, j.Status, j.StartedAt, j.Total)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update сохраняет статус и счётчики задачи
+func (r *jobsRepository) Update(ctx context.Context, j models.Job) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(
+		ctx,
+		queryUpdateJobRun,
+		j.Id,
+		j.Status,
+		j.Error,
+		j.FinishedAt,
+		j.Total,
+		j.Processed,
+		j.Succeeded,
+		j.Failed,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *jobsRepository) Get(ctx context.Context, id string) (res models.Job, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err = r.pg.GetContext(ctx, &res, queryGetJobRun, id)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// Fetch возвращает последние limit запусков задач, от новых к старым
+func (r *jobsRepository) Fetch(ctx context.Context, limit int) (res []*models.Job, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.Job, 0)
+
+	err = r.pg.SelectContext(ctx, &res, queryFetchJobRuns, limit)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// SaveCampaignResult обновляет последнюю ошибку обновления РК и счётчик неудач подряд.
+// Успешное обновление сбрасывает счётчик
+func (r *jobsRepository) SaveCampaignResult(ctx context.Context, campaignId string, refreshErr error) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	query, args := queryCampaignRefreshSucceeded, []any{campaignId}
+	if refreshErr != nil {
+		query, args = queryCampaignRefreshFailed, []any{campaignId, refreshErr.Error()}
+	}
+
+	_, err := r.pg.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/timmbarton/utils/tracing"
+)
+
+type leasesRepository struct {
+	pg *sqlx.DB
+}
+
+// TryAcquire захватывает или продлевает аренду name на ttl.
+// Возвращает false, если аренда принадлежит другому владельцу и ещё не истекла
+func (r *leasesRepository) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (ok bool, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	current := ""
+
+	err = r.pg.GetContext(ctx, &current, queryAcquireLease, name, holder, ttl.Milliseconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return current == holder, nil
+}
+
+// Release освобождает аренду, если она принадлежит holder
+func (r *leasesRepository) Release(ctx context.Context, name, holder string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(ctx, queryReleaseLease, name, holder)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
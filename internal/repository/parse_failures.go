@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+)
+
+type parseFailuresRepository struct {
+	pg *sqlx.DB
+}
+
+// Create сохраняет страницу, если по этой РК с той же причиной за последние сутки страница ещё не сохранялась
+func (r *parseFailuresRepository) Create(ctx context.Context, f models.ParseFailure) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(ctx, queryCreateParseFailure, f.CampaignId, f.Reason, f.Page)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteOld удаляет страницы, сохранённые раньше before
+func (r *parseFailuresRepository) DeleteOld(ctx context.Context, before time.Time) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(ctx, queryDeleteOldParseFailures, before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
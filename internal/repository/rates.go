@@ -7,6 +7,8 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/timmbarton/utils/tracing"
 	"github.com/timmbarton/utils/types/dates"
+
+	"backend/internal/models"
 )
 
 type ratesRepository struct {
@@ -29,3 +31,17 @@ func (r *ratesRepository) Create(ctx context.Context, date dates.Date, rate deci
 
 	return nil
 }
+
+func (r *ratesRepository) Fetch(ctx context.Context, from, to dates.Date) (res []*models.Rate, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.Rate, 0)
+
+	err = r.pg.SelectContext(ctx, &res, queryFetchRates, from, to)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/shopspring/decimal"
@@ -12,9 +13,15 @@ import (
 )
 
 type Repositories struct {
-	Campaigns CampaignsRepository
-	Stats     StatsRepository
-	Rates     RatesRepository
+	Campaigns     CampaignsRepository
+	Stats         StatsRepository
+	Rates         RatesRepository
+	Revisions     RevisionsRepository
+	Tags          TagsRepository
+	Folders       FoldersRepository
+	Jobs          JobsRepository
+	Leases        LeasesRepository
+	ParseFailures ParseFailuresRepository
 }
 
 func New(pg *sqlx.DB) *Repositories {
@@ -28,18 +35,88 @@ func New(pg *sqlx.DB) *Repositories {
 		Rates: &ratesRepository{
 			pg: pg,
 		},
+		Revisions: &revisionsRepository{
+			pg: pg,
+		},
+		Tags: &tagsRepository{
+			pg: pg,
+		},
+		Folders: &foldersRepository{
+			pg: pg,
+		},
+		Jobs: &jobsRepository{
+			pg: pg,
+		},
+		Leases: &leasesRepository{
+			pg: pg,
+		},
+		ParseFailures: &parseFailuresRepository{
+			pg: pg,
+		},
 	}
 }
 
 type CampaignsRepository interface {
-	Create(ctx context.Context, c models.Campaign) error
-	Fetch(ctx context.Context) (res []*models.Campaign, err error)
+	Create(ctx context.Context, c models.Campaign) (created bool, err error)
+	Update(ctx context.Context, c models.Campaign) error
+	UpdateInfo(ctx context.Context, id string, name, notes, client *string) error
+	Fetch(
+		ctx context.Context,
+		f models.CampaignsFilter,
+		p models.CampaignsPagination,
+	) (res []*models.CampaignListItem, err error)
+	FetchNotArchived(ctx context.Context) (res []*models.Campaign, err error)
+	Get(ctx context.Context, id string) (res models.Campaign, err error)
+	Archive(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
 }
 
 type StatsRepository interface {
 	Create(ctx context.Context, campaignId string, stats []*tgads.Stats) error
+	Fetch(ctx context.Context, campaignId string, from, to dates.Date) (res []*models.Stats, err error)
+	FetchTotals(ctx context.Context, campaignId string) (res models.CampaignTotals, err error)
+	CreateHourly(ctx context.Context, campaignId string, stats []*tgads.Stats) error
+	FetchHourly(ctx context.Context, campaignId string, from, to time.Time) (res []*models.HourlyStats, err error)
 }
 
 type RatesRepository interface {
 	Create(ctx context.Context, date dates.Date, rate decimal.Decimal) error
+	Fetch(ctx context.Context, from, to dates.Date) (res []*models.Rate, err error)
+}
+
+type RevisionsRepository interface {
+	Create(ctx context.Context, c models.Campaign) error
+	Fetch(ctx context.Context, campaignId string) (res []*models.CampaignRevision, err error)
+}
+
+type TagsRepository interface {
+	Add(ctx context.Context, campaignId string, tags []string) error
+	Remove(ctx context.Context, campaignId string, tag string) error
+	Fetch(ctx context.Context) (res []*models.Tag, err error)
+}
+
+type FoldersRepository interface {
+	Create(ctx context.Context, name string) (res models.Folder, err error)
+	Get(ctx context.Context, id int64) (res models.Folder, err error)
+	Fetch(ctx context.Context) (res []*models.Folder, err error)
+	Delete(ctx context.Context, id int64) error
+	SetCampaign(ctx context.Context, campaignId string, folderId *int64) error
+}
+
+type JobsRepository interface {
+	Create(ctx context.Context, j models.Job) error
+	Update(ctx context.Context, j models.Job) error
+	Get(ctx context.Context, id string) (res models.Job, err error)
+	Fetch(ctx context.Context, limit int) (res []*models.Job, err error)
+	SaveCampaignResult(ctx context.Context, campaignId string, refreshErr error) error
+}
+
+type LeasesRepository interface {
+	TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (ok bool, err error)
+	Release(ctx context.Context, name, holder string) error
+}
+
+type ParseFailuresRepository interface {
+	Create(ctx context.Context, f models.ParseFailure) error
+	DeleteOld(ctx context.Context, before time.Time) error
 }
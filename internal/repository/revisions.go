@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+)
+
+type revisionsRepository struct {
+	pg *sqlx.DB
+}
+
+// Create сохраняет текущую версию объявления РК, если она отличается от последней сохранённой
+func (r *revisionsRepository) Create(ctx context.Context, c models.Campaign) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(
+		ctx,
+		queryCreateCampaignRevision,
+		c.Id,
+		c.Text,
+		c.ButtonText,
+		c.Link,
+		c.Active,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *revisionsRepository) Fetch(ctx context.Context, campaignId string) (res []*models.CampaignRevision, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.CampaignRevision, 0)
+
+	err = r.pg.SelectContext(ctx, &res, queryFetchCampaignRevisions, campaignId)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
@@ -18,8 +18,52 @@ const (
 		                 spend = EXCLUDED.spend, 
 		                 cpm = EXCLUDED.cpm
 	`
+	queryFetchStats = `
+		SELECT campaign_id, "date", views, clicks, actions, spend, cpm
+		FROM tgads.stats
+		WHERE campaign_id = $1::text
+		  AND "date" BETWEEN $2::date AND $3::date
+		ORDER BY "date"
+	`
+	queryCreateHourlyStats = `
+		INSERT INTO tgads.hourly_stats(campaign_id, datetime, views, clicks, actions, spend, cpm)
+		VALUES ($1::text,
+				unnest($2::timestamptz[]),
+				unnest($3::int[]),
+				unnest($4::int[]),
+				unnest($5::int[]),
+				unnest($6::decimal[]),
+				unnest($7::decimal[]))
+		ON CONFLICT (campaign_id, datetime) 
+		             DO UPDATE SET 
+		                 views = EXCLUDED.views, 
+		                 clicks = EXCLUDED.clicks, 
+		                 actions = EXCLUDED.actions, 
+		                 spend = EXCLUDED.spend, 
+		                 cpm = EXCLUDED.cpm
+	`
+	queryFetchHourlyStats = `
+		SELECT campaign_id, datetime, views, clicks, actions, spend, cpm
+		FROM tgads.hourly_stats
+		WHERE campaign_id = $1::text
+		  AND datetime >= $2::timestamptz
+		  AND datetime < $3::timestamptz
+		ORDER BY datetime
+	`
+	queryFetchStatsTotals = `
+		SELECT COALESCE(SUM(views), 0)                   AS views,
+		       COALESCE(SUM(clicks), 0)                  AS clicks,
+		       COALESCE(SUM(actions), 0)                 AS actions,
+		       COALESCE(SUM(spend), 0)                   AS spend,
+		       MIN("date")                               AS first_date,
+		       MAX("date")                               AS last_date,
+		       COUNT(*) FILTER (WHERE views > 0)         AS days_active
+		FROM tgads.stats
+		WHERE campaign_id = $1::text
+	`
 	queryCreateCampaign = `
-		INSERT INTO tgads.campaigns(id, name, stats_csv_link, budget_csv_link, text, button_text, link, active)
+		INSERT INTO tgads.campaigns(id, name, stats_csv_link, budget_csv_link, text, button_text, link, active,
+		                            title, cpm_bid, budget, daily_budget, channels, languages, topics, ad_created_at)
 		VALUES ($1::text, 
 		        $2::text,
 				$3::text,
@@ -27,16 +71,259 @@ const (
 				$5::text,
 				$6::text,
 				$7::text,
-				$8::boolean)
+				$8::boolean,
+				$9::text,
+				$10::decimal,
+				$11::decimal,
+				$12::decimal,
+				$13::text[],
+				$14::text[],
+				$15::text[],
+				$16::timestamptz)
 		ON CONFLICT (id) DO NOTHING
 	`
+	queryUpdateCampaign = `
+		UPDATE tgads.campaigns
+		SET stats_csv_link = $2::text,
+		    budget_csv_link = $3::text,
+		    text = $4::text,
+		    button_text = $5::text,
+		    link = $6::text,
+		    active = $7::boolean,
+		    title = $8::text,
+		    cpm_bid = $9::decimal,
+		    budget = $10::decimal,
+		    daily_budget = $11::decimal,
+		    channels = $12::text[],
+		    languages = $13::text[],
+		    topics = $14::text[],
+		    ad_created_at = $15::timestamptz
+		WHERE id = $1::text
+	`
+	queryUpdateCampaignInfo = `
+		UPDATE tgads.campaigns
+		SET name = COALESCE($2::text, name),
+		    notes = COALESCE($3::text, notes),
+		    client = COALESCE($4::text, client)
+		WHERE id = $1::text
+	`
+	// queryFetchCampaigns - шаблон запроса списка РК.
+	// %[1]s - поле сортировки, %[2]s - его тип, %[3]s - оператор сравнения с курсором, %[4]s - направление сортировки
 	queryFetchCampaigns = `
+		SELECT *
+		FROM (SELECT c.*,
+		             ` + subqueryCampaignTags + ` AS tags,
+		             COALESCE(s.total_spend, 0)          AS total_spend,
+		             COALESCE(s.views_7d, 0)             AS views_7d,
+		             e.last_error                        AS refresh_error,
+		             e.last_error_at                     AS refresh_error_at,
+		             COALESCE(e.consecutive_failures, 0) AS refresh_failures
+		      FROM tgads.campaigns c
+		               LEFT JOIN tgads.campaign_refresh_errors e ON e.campaign_id = c.id
+		               LEFT JOIN (SELECT campaign_id,
+		                                 SUM(spend)                                          AS total_spend,
+		                                 SUM(views) FILTER (WHERE "date" > CURRENT_DATE - 7) AS views_7d
+		                          FROM tgads.stats
+		                          GROUP BY campaign_id) s ON s.campaign_id = c.id
+		      WHERE ($1::text IS NULL OR EXISTS(SELECT 1
+		                                         FROM tgads.campaign_tags ct
+		                                                  JOIN tgads.tags t ON t.id = ct.tag_id
+		                                         WHERE ct.campaign_id = c.id
+		                                           AND t.name = $1::text))
+		        AND ($2::boolean IS NULL OR c.active = $2::boolean)
+		        AND ($3::text IS NULL OR c.name ILIKE '%%' || $3::text || '%%' OR c.text ILIKE '%%' || $3::text || '%%')
+		        AND ($7::bigint IS NULL OR c.folder_id = $7::bigint)) c
+		WHERE ($4::text IS NULL OR (c.%[1]s, c.id) %[3]s ($4::text::%[2]s, $5::text))
+		ORDER BY c.%[1]s %[4]s, c.id %[4]s
+		LIMIT $6::int
+	`
+	queryFetchNotArchivedCampaigns = `
 		SELECT *
 		FROM tgads.campaigns
+		WHERE archived_at IS NULL
+	`
+	queryArchiveCampaign = `
+		UPDATE tgads.campaigns
+		SET archived_at = COALESCE(archived_at, NOW())
+		WHERE id = $1::text
+	`
+	queryDeleteCampaign = `
+		WITH f AS (
+			DELETE FROM tgads.parse_failures
+			WHERE campaign_id = $1::text
+		)
+		DELETE FROM tgads.campaigns
+		WHERE id = $1::text
+	`
+	queryGetCampaign = `
+		SELECT c.*,
+		       ` + subqueryCampaignTags + ` AS tags
+		FROM tgads.campaigns c
+		WHERE c.id = $1::text
+	`
+	queryCreateCampaignRevision = `
+		INSERT INTO tgads.campaign_revisions(campaign_id, text, button_text, link, active)
+		SELECT $1::text, $2::text, $3::text, $4::text, $5::boolean
+		WHERE NOT EXISTS (SELECT 1
+		                  FROM (SELECT text, button_text, link, active
+		                        FROM tgads.campaign_revisions
+		                        WHERE campaign_id = $1::text
+		                        ORDER BY id DESC
+		                        LIMIT 1) AS last
+		                  WHERE last.text = $2::text
+		                    AND last.button_text = $3::text
+		                    AND last.link = $4::text
+		                    AND last.active = $5::boolean)
+	`
+	queryFetchCampaignRevisions = `
+		SELECT id, campaign_id, text, button_text, link, active, created_at
+		FROM tgads.campaign_revisions
+		WHERE campaign_id = $1::text
+		ORDER BY id DESC
+	`
+	subqueryCampaignTags = `
+		COALESCE((SELECT array_agg(t.name ORDER BY t.name)
+		          FROM tgads.campaign_tags ct
+		                   JOIN tgads.tags t ON t.id = ct.tag_id
+		          WHERE ct.campaign_id = c.id), '{}')
+	`
+	queryAddCampaignTags = `
+		WITH t AS (
+			INSERT INTO tgads.tags(name)
+			SELECT DISTINCT unnest($2::text[])
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		)
+		INSERT INTO tgads.campaign_tags(campaign_id, tag_id)
+		SELECT $1::text, t.id
+		FROM t
+		ON CONFLICT DO NOTHING
+	`
+	queryRemoveCampaignTag = `
+		DELETE FROM tgads.campaign_tags ct
+		USING tgads.tags t
+		WHERE t.id = ct.tag_id
+		  AND ct.campaign_id = $1::text
+		  AND t.name = $2::text
+	`
+	queryFetchTags = `
+		SELECT t.id, t.name, COUNT(ct.campaign_id) AS campaigns_count
+		FROM tgads.tags t
+		         LEFT JOIN tgads.campaign_tags ct ON ct.tag_id = t.id
+		GROUP BY t.id, t.name
+		ORDER BY t.name
+	`
+	queryCreateFolder = `
+		WITH f AS (
+			INSERT INTO tgads.folders(name)
+			VALUES ($1::text)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id, name
+		)
+		SELECT f.id, f.name, (SELECT COUNT(*) FROM tgads.campaigns c WHERE c.folder_id = f.id) AS campaigns_count
+		FROM f
+	`
+	queryGetFolder = `
+		SELECT f.id, f.name, COUNT(c.id) AS campaigns_count
+		FROM tgads.folders f
+		         LEFT JOIN tgads.campaigns c ON c.folder_id = f.id
+		WHERE f.id = $1::bigint
+		GROUP BY f.id, f.name
+	`
+	queryFetchFolders = `
+		SELECT f.id, f.name, COUNT(c.id) AS campaigns_count
+		FROM tgads.folders f
+		         LEFT JOIN tgads.campaigns c ON c.folder_id = f.id
+		GROUP BY f.id, f.name
+		ORDER BY f.name
+	`
+	queryDeleteFolder = `
+		DELETE FROM tgads.folders
+		WHERE id = $1::bigint
+	`
+	querySetCampaignFolder = `
+		UPDATE tgads.campaigns
+		SET folder_id = $2::bigint
+		WHERE id = $1::text
+	`
+	queryCreateJobRun = `
+		INSERT INTO tgads.job_runs(id, type, status, started_at, total)
+		VALUES ($1::uuid, $2::text, $3::text, $4::timestamptz, $5::int)
+	`
+	queryUpdateJobRun = `
+		UPDATE tgads.job_runs
+		SET status = $2::text,
+		    error = $3::text,
+		    finished_at = $4::timestamptz,
+		    total = $5::int,
+		    processed = $6::int,
+		    succeeded = $7::int,
+		    failed = $8::int
+		WHERE id = $1::uuid
+	`
+	queryGetJobRun = `
+		SELECT id, type, status, error, started_at, finished_at, total, processed, succeeded, failed
+		FROM tgads.job_runs
+		WHERE id = $1::uuid
+	`
+	queryFetchJobRuns = `
+		SELECT id, type, status, error, started_at, finished_at, total, processed, succeeded, failed
+		FROM tgads.job_runs
+		ORDER BY started_at DESC
+		LIMIT $1::int
+	`
+	queryCampaignRefreshFailed = `
+		INSERT INTO tgads.campaign_refresh_errors(campaign_id, last_error, last_error_at, consecutive_failures)
+		VALUES ($1::text, $2::text, NOW(), 1)
+		ON CONFLICT (campaign_id) DO UPDATE SET
+		                 last_error = EXCLUDED.last_error,
+		                 last_error_at = EXCLUDED.last_error_at,
+		                 consecutive_failures = tgads.campaign_refresh_errors.consecutive_failures + 1
+	`
+	queryCampaignRefreshSucceeded = `
+		INSERT INTO tgads.campaign_refresh_errors(campaign_id, last_success_at, consecutive_failures)
+		VALUES ($1::text, NOW(), 0)
+		ON CONFLICT (campaign_id) DO UPDATE SET
+		                 last_success_at = EXCLUDED.last_success_at,
+		                 consecutive_failures = 0
+	`
+	queryAcquireLease = `
+		INSERT INTO tgads.leases(name, holder, expires_at)
+		VALUES ($1::text, $2::text, NOW() + $3::bigint * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+		                 holder = EXCLUDED.holder,
+		                 expires_at = EXCLUDED.expires_at
+		WHERE tgads.leases.holder = EXCLUDED.holder
+		   OR tgads.leases.expires_at < NOW()
+		RETURNING holder
+	`
+	queryReleaseLease = `
+		DELETE FROM tgads.leases
+		WHERE name = $1::text
+		  AND holder = $2::text
+	`
+	queryCreateParseFailure = `
+		INSERT INTO tgads.parse_failures(campaign_id, reason, page)
+		SELECT $1::text, $2::text, $3::bytea
+		WHERE NOT EXISTS (SELECT 1
+		                  FROM tgads.parse_failures
+		                  WHERE campaign_id = $1::text
+		                    AND reason = $2::text
+		                    AND created_at > NOW() - INTERVAL '1 day')
+	`
+	queryDeleteOldParseFailures = `
+		DELETE FROM tgads.parse_failures
+		WHERE created_at < $1::timestamptz
 	`
 	queryCreateRate = `
 		INSERT INTO tgads.rates(date, rate)
 		VALUES ($1::date,$2::decimal)
 		ON CONFLICT (DATE) DO UPDATE SET rate = EXCLUDED.rate
 	`
+	queryFetchRates = `
+		SELECT "date", rate
+		FROM tgads.rates
+		WHERE "date" BETWEEN $1::date AND $2::date
+		ORDER BY "date"
+	`
 )
@@ -7,7 +7,9 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/timmbarton/utils/tracing"
+	"github.com/timmbarton/utils/types/dates"
 
+	"backend/internal/models"
 	"backend/pkg/tgads"
 )
 
@@ -19,6 +21,24 @@ func (r *statsRepository) Create(ctx context.Context, campaignId string, stats [
 	ctx, span := tracing.NewSpan(ctx)
 	defer span.End()
 
+	return r.create(ctx, queryCreateStats, campaignId, stats, time.DateTime)
+}
+
+func (r *statsRepository) CreateHourly(ctx context.Context, campaignId string, stats []*tgads.Stats) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	return r.create(ctx, queryCreateHourlyStats, campaignId, stats, time.RFC3339)
+}
+
+// create сохраняет статистику одним запросом, передавая колонки массивами
+func (r *statsRepository) create(
+	ctx context.Context,
+	query string,
+	campaignId string,
+	stats []*tgads.Stats,
+	datetimeFormat string,
+) error {
 	batch := struct {
 		Datetime pq.StringArray
 		Views    pq.Int64Array
@@ -36,7 +56,7 @@ func (r *statsRepository) Create(ctx context.Context, campaignId string, stats [
 	}
 
 	for _, item := range stats {
-		batch.Datetime = append(batch.Datetime, item.Datetime.Format(time.DateTime))
+		batch.Datetime = append(batch.Datetime, item.Datetime.Format(datetimeFormat))
 		batch.Views = append(batch.Views, int64(item.Views))
 		batch.Clicks = append(batch.Clicks, int64(item.Clicks))
 		batch.Action = append(batch.Action, int64(item.Actions))
@@ -46,7 +66,7 @@ func (r *statsRepository) Create(ctx context.Context, campaignId string, stats [
 
 	_, err := r.pg.ExecContext(
 		ctx,
-		queryCreateStats,
+		query,
 		campaignId,
 		batch.Datetime,
 		batch.Views,
@@ -61,3 +81,55 @@ func (r *statsRepository) Create(ctx context.Context, campaignId string, stats [
 
 	return nil
 }
+
+func (r *statsRepository) Fetch(ctx context.Context, campaignId string, from, to dates.Date) (res []*models.Stats, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.Stats, 0)
+
+	err = r.pg.SelectContext(
+		ctx,
+		&res,
+		queryFetchStats,
+		campaignId,
+		from,
+		to,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *statsRepository) FetchTotals(ctx context.Context, campaignId string) (res models.CampaignTotals, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err = r.pg.GetContext(ctx, &res, queryFetchStatsTotals, campaignId)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// FetchHourly возвращает почасовую статистику РК за период [from, to)
+func (r *statsRepository) FetchHourly(
+	ctx context.Context,
+	campaignId string,
+	from, to time.Time,
+) (res []*models.HourlyStats, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.HourlyStats, 0)
+
+	err = r.pg.SelectContext(ctx, &res, queryFetchHourlyStats, campaignId, from, to)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
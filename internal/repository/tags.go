@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+)
+
+type tagsRepository struct {
+	pg *sqlx.DB
+}
+
+// Add создаёт недостающие теги и привязывает их к РК
+func (r *tagsRepository) Add(ctx context.Context, campaignId string, tags []string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := r.pg.ExecContext(ctx, queryAddCampaignTags, campaignId, pq.StringArray(tags))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Remove отвязывает тег от РК. Сам тег остаётся.
+// Если тега нет или РК им не помечена, возвращает sql.ErrNoRows
+func (r *tagsRepository) Remove(ctx context.Context, campaignId string, tag string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err := r.pg.ExecContext(ctx, queryRemoveCampaignTag, campaignId, tag)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *tagsRepository) Fetch(ctx context.Context) (res []*models.Tag, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.Tag, 0)
+
+	err = r.pg.SelectContext(ctx, &res, queryFetchTags)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
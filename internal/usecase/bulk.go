@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+	"backend/pkg/errlist"
+	"backend/pkg/tgads"
+)
+
+type CreateCampaignsBulkRequest struct {
+	Items []*BulkCampaignItem `validate:"required,min=1,max=500,dive,required"`
+}
+
+type BulkCampaignItem struct {
+	Link string `json:"link" validate:"required"`
+	Name string `json:"name"`
+}
+
+// Результаты добавления РК из пакета
+const (
+	BulkStatusCreated       = "created"
+	BulkStatusAlreadyExists = "already_exists"
+	BulkStatusInvalidLink   = "invalid_link"
+	BulkStatusNotFound      = "not_found"
+	// BulkStatusRetryLater - ads.telegram.org временно недоступен, ссылку можно отправить повторно
+	BulkStatusRetryLater = "retry_later"
+	BulkStatusFailed     = "failed"
+)
+
+type BulkCampaignResult struct {
+	Link   string `json:"link"`
+	Id     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type CreateCampaignsBulkResponse struct {
+	Results []*BulkCampaignResult `json:"results"`
+	// Job - фоновая задача загрузки статистики добавленных РК
+	Job *models.Job `json:"job,omitempty"`
+}
+
+// CreateCampaignsBulk добавляет пакет РК пулом воркеров и возвращает результат по каждой ссылке.
+// Статистика добавленных РК загружается в фоне
+func (uc *useCase) CreateCampaignsBulk(
+	ctx context.Context,
+	req CreateCampaignsBulkRequest,
+) (res CreateCampaignsBulkResponse, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	// Проверяем до сохранения РК, чтобы при остановке сервиса не добавить РК без статистики
+	if !uc.running.add() {
+		return res, errlist.ErrShuttingDown
+	}
+
+	// Если загрузка не ушла в фон, её место в running освобождается здесь
+	launched := false
+	defer func() {
+		if !launched {
+			uc.running.done()
+		}
+	}()
+
+	res.Results = make([]*BulkCampaignResult, len(req.Items))
+	pending := make([]int, 0, len(req.Items))
+	seen := make(map[string]struct{}, len(req.Items))
+
+	for i, item := range req.Items {
+		link := strings.TrimSpace(item.Link)
+		res.Results[i] = &BulkCampaignResult{Link: link}
+
+		id, err := uc.tgads.CampaignId(link)
+		if err != nil {
+			res.Results[i].Status = BulkStatusInvalidLink
+			continue
+		}
+
+		res.Results[i].Id = id
+
+		// Повтор ссылки внутри пакета
+		if _, ok := seen[id]; ok {
+			res.Results[i].Status = BulkStatusAlreadyExists
+			continue
+		}
+
+		seen[id] = struct{}{}
+		pending = append(pending, i)
+	}
+
+	created := make([]tgads.Campaign, len(req.Items))
+
+	forEach(uc.cfg.RefreshStatsLoadingWorkersCount, pending, func(i int) {
+		result := res.Results[i]
+
+		_, err := uc.r.Campaigns.Get(ctx, result.Id)
+		switch {
+		case err == nil:
+			result.Status = BulkStatusAlreadyExists
+			return
+		case !errors.Is(err, sql.ErrNoRows):
+			result.Status, result.Error = BulkStatusFailed, err.Error()
+			return
+		}
+
+		raw, err := uc.getCampaign(tgads.WithProxySession(ctx), result.Link)
+		if errors.Is(err, tgads.ErrCampaignNotFound) {
+			result.Status = BulkStatusNotFound
+			return
+		}
+		if tgads.IsTemporary(err) {
+			result.Status, result.Error = BulkStatusRetryLater, err.Error()
+			return
+		}
+		if err != nil {
+			result.Status, result.Error = BulkStatusFailed, err.Error()
+			return
+		}
+
+		c := newCampaign(req.Items[i].Name, raw)
+
+		inserted, err := uc.r.Campaigns.Create(ctx, c)
+		if err == nil && inserted {
+			err = uc.r.Revisions.Create(ctx, c)
+		}
+		if err != nil {
+			result.Status, result.Error = BulkStatusFailed, err.Error()
+			return
+		}
+
+		// РК добавили параллельно с пакетом
+		if !inserted {
+			result.Status = BulkStatusAlreadyExists
+			return
+		}
+
+		result.Status = BulkStatusCreated
+		created[i] = raw
+	})
+
+	toImport := make([]tgads.Campaign, 0, len(pending))
+	for _, i := range pending {
+		if res.Results[i].Status == BulkStatusCreated {
+			toImport = append(toImport, created[i])
+		}
+	}
+
+	if len(toImport) == 0 {
+		return res, nil
+	}
+
+	j := uc.startJob(ctx, models.JobTypeImportStats, len(toImport))
+
+	launched = true
+
+	go func() {
+		defer uc.running.done()
+
+		ctx := uc.ctx
+
+		forEach(uc.cfg.RefreshStatsLoadingWorkersCount, toImport, func(raw tgads.Campaign) {
+			if ctx.Err() != nil {
+				return
+			}
+
+			_, err := uc.importStats(tgads.WithProxySession(ctx), raw)
+			if err != nil {
+				log.Println(err)
+			}
+
+			uc.campaignDone(ctx, j, raw.Id, err)
+		})
+
+		uc.logLimiterStats()
+
+		uc.finishJob(ctx, j, nil)
+	}()
+
+	snapshot := j.snapshot()
+	res.Job = &snapshot
+
+	return res, nil
+}
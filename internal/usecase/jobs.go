@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"backend/internal/models"
+)
+
+// jobsTTL - сколько хранится информация о завершённой задаче
+const jobsTTL = 24 * time.Hour
+
+// jobsCancelGrace - сколько Stop ждёт задачи после отмены их контекста
+const jobsCancelGrace = 5 * time.Second
+
+// startJob регистрирует задачу и сохраняет её запуск в историю
+func (uc *useCase) startJob(ctx context.Context, jobType string, total int) *job {
+	j := uc.jobs.start(jobType, total)
+
+	uc.saveJobStart(ctx, j)
+
+	return j
+}
+
+// startJobIfIdle регистрирует задачу, если задача этого типа ещё не выполняется.
+// Иначе возвращает выполняющуюся задачу и started = false
+func (uc *useCase) startJobIfIdle(ctx context.Context, jobType string) (j *job, started bool) {
+	j, started = uc.jobs.startIfIdle(jobType, 0)
+	if started {
+		uc.saveJobStart(ctx, j)
+	}
+
+	return j, started
+}
+
+func (uc *useCase) saveJobStart(ctx context.Context, j *job) {
+	err := uc.r.Jobs.Create(context.WithoutCancel(ctx), j.snapshot())
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// campaignDone фиксирует результат обновления РК в задаче и в последних ошибках РК.
+// Ошибки из-за остановки сервиса не считаются ошибками РК
+func (uc *useCase) campaignDone(ctx context.Context, j *job, campaignId string, refreshErr error) {
+	j.done(campaignId, refreshErr)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	err := uc.r.Jobs.SaveCampaignResult(ctx, campaignId, refreshErr)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// finishJob завершает задачу и сохраняет итог в историю.
+// Если контекст отменён, задача помечается прерванной
+func (uc *useCase) finishJob(ctx context.Context, j *job, jobErr error) {
+	j.finish(jobErr, ctx.Err() != nil)
+
+	err := uc.r.Jobs.Update(context.WithoutCancel(ctx), j.snapshot())
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// running отслеживает выполняющиеся задачи, чтобы Stop мог их дождаться
+type running struct {
+	mu      sync.Mutex
+	closed  bool
+	wg      sync.WaitGroup
+	stopped chan struct{}
+}
+
+// add регистрирует задачу. Возвращает false, если сервис уже останавливается
+func (r *running) add() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return false
+	}
+
+	r.wg.Add(1)
+
+	return true
+}
+
+func (r *running) done() {
+	r.wg.Done()
+}
+
+// close запрещает запуск новых задач и возвращает канал, который закроется после завершения текущих
+func (r *running) close() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.closed {
+		r.closed = true
+		r.stopped = make(chan struct{})
+
+		go func() {
+			r.wg.Wait()
+			close(r.stopped)
+		}()
+	}
+
+	return r.stopped
+}
+
+// waitJobs ждёт завершения задач до дедлайна ctx. Если дедлайн наступил,
+// отменяет корневой контекст задач и даёт им jobsCancelGrace на запись результатов
+func (uc *useCase) waitJobs(ctx context.Context) error {
+	stopped := uc.running.close()
+
+	select {
+	case <-stopped:
+		uc.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Println("shutdown deadline exceeded, interrupting running jobs")
+
+	uc.cancel()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(jobsCancelGrace):
+		return errors.New("running jobs did not stop in time")
+	}
+}
+
+// jobs хранит задачи обновления статистики в памяти процесса
+type jobs struct {
+	mu   sync.RWMutex
+	list map[string]*job
+}
+
+func newJobs() *jobs {
+	return &jobs{
+		list: make(map[string]*job),
+	}
+}
+
+// start регистрирует новую задачу
+func (j *jobs) start(jobType string, total int) *job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.add(jobType, total)
+}
+
+// startIfIdle проверяет и регистрирует задачу под одной блокировкой,
+// чтобы одновременные запуски не создали две задачи одного типа
+func (j *jobs) startIfIdle(jobType string, total int) (res *job, started bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, item := range j.list {
+		data := item.snapshot()
+		if data.Type == jobType && data.Status == models.JobStatusRunning {
+			return item, false
+		}
+	}
+
+	return j.add(jobType, total), true
+}
+
+// add добавляет задачу, вызывается под j.mu. Завершённые задачи старше jobsTTL удаляются
+func (j *jobs) add(jobType string, total int) *job {
+	now := time.Now()
+
+	item := &job{
+		data: models.Job{
+			Id:        uuid.NewString(),
+			Type:      jobType,
+			Status:    models.JobStatusRunning,
+			StartedAt: now,
+			Total:     total,
+			Results:   make([]*models.JobCampaignResult, 0, total),
+		},
+	}
+
+	for id, old := range j.list {
+		data := old.snapshot()
+		if data.FinishedAt != nil && now.Sub(*data.FinishedAt) > jobsTTL {
+			delete(j.list, id)
+		}
+	}
+
+	j.list[item.data.Id] = item
+
+	return item
+}
+
+func (j *jobs) get(id string) (res *job, ok bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	res, ok = j.list[id]
+
+	return res, ok
+}
+
+// job - задача обновления статистики, которую одновременно обновляют несколько воркеров
+type job struct {
+	mu   sync.Mutex
+	data models.Job
+}
+
+func (j *job) id() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.data.Id
+}
+
+// setTotal задаёт количество РК, когда оно стало известно после запуска задачи
+func (j *job) setTotal(total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.data.Total = total
+	j.data.Results = make([]*models.JobCampaignResult, 0, total)
+}
+
+// done фиксирует результат обработки одной РК
+func (j *job) done(campaignId string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	result := &models.JobCampaignResult{
+		CampaignId: campaignId,
+		Success:    err == nil,
+	}
+
+	j.data.Processed++
+
+	if err != nil {
+		result.Error = err.Error()
+		j.data.Failed++
+	} else {
+		j.data.Succeeded++
+	}
+
+	j.data.Results = append(j.data.Results, result)
+}
+
+// finish завершает задачу. Если err != nil, задача считается упавшей целиком
+func (j *job) finish(err error, interrupted bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+
+	j.data.FinishedAt = &now
+	j.data.Status = models.JobStatusFinished
+
+	if err != nil {
+		j.data.Status = models.JobStatusFailed
+		j.data.Error = err.Error()
+	}
+
+	if interrupted {
+		j.data.Status = models.JobStatusInterrupted
+	}
+}
+
+// snapshot возвращает копию состояния задачи
+func (j *job) snapshot() (res models.Job) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	res = j.data
+	res.Results = append([]*models.JobCampaignResult(nil), j.data.Results...)
+
+	return res
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"backend/internal/repository"
+)
+
+// schedulerLease - имя аренды, владелец которой выполняет задачи по расписанию
+const schedulerLease = "scheduler"
+
+const defaultLeaderLeaseTTL = 30 * time.Second
+
+// LeaderConfig описывает выбор реплики, которая выполняет задачи по расписанию
+type LeaderConfig struct {
+	// Disabled - выполнять задачи по расписанию на каждой реплике
+	Disabled bool
+	// LeaseTTL - через сколько после последнего продления аренду может забрать другая реплика
+	LeaseTTL time.Duration
+}
+
+// leader держит аренду в БД, продлевая её каждые LeaseTTL / 3.
+// Если реплика-лидер перестаёт продлевать аренду, её забирает другая реплика
+type leader struct {
+	cfg      LeaderConfig
+	r        repository.LeasesRepository
+	holder   string
+	isLeader atomic.Bool
+	started  atomic.Bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+
+	// lost закрывается при потере аренды, пересоздаётся при её получении
+	mu   sync.Mutex
+	lost chan struct{}
+}
+
+func newLeader(cfg LeaderConfig, r repository.LeasesRepository) *leader {
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultLeaderLeaseTTL
+	}
+
+	hostname, _ := os.Hostname()
+
+	lost := make(chan struct{})
+	close(lost)
+
+	return &leader{
+		cfg:    cfg,
+		r:      r,
+		holder: hostname + "-" + uuid.NewString(),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		lost:   lost,
+	}
+}
+
+func (l *leader) start() {
+	if l.cfg.Disabled || l.started.Swap(true) {
+		return
+	}
+
+	go l.run()
+}
+
+func (l *leader) run() {
+	defer close(l.done)
+
+	t := time.NewTicker(l.cfg.LeaseTTL / 3)
+	defer t.Stop()
+
+	l.heartbeat()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+			l.heartbeat()
+		}
+	}
+}
+
+func (l *leader) heartbeat() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.LeaseTTL/3)
+	defer cancel()
+
+	ok, err := l.r.TryAcquire(ctx, schedulerLease, l.holder, l.cfg.LeaseTTL)
+	if err != nil {
+		log.Println(err)
+		ok = false
+	}
+
+	if l.setLeader(ok) {
+		if ok {
+			log.Printf("%s became scheduler leader", l.holder)
+		} else {
+			log.Printf("%s lost scheduler leadership", l.holder)
+		}
+	}
+}
+
+// setLeader меняет признак лидерства и сообщает, изменился ли он
+func (l *leader) setLeader(ok bool) (changed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.isLeader.Swap(ok) == ok {
+		return false
+	}
+
+	if ok {
+		l.lost = make(chan struct{})
+	} else {
+		close(l.lost)
+	}
+
+	return true
+}
+
+// fence возвращает контекст, который отменяется при потере аренды.
+// Задача по расписанию, пережившая аренду, не продолжает работу параллельно с новым лидером
+func (l *leader) fence(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.cfg.Disabled {
+		return context.WithCancel(ctx)
+	}
+
+	l.mu.Lock()
+	lost := l.lost
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		select {
+		case <-lost:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// IsLeader сообщает, должна ли реплика выполнять задачи по расписанию
+func (l *leader) IsLeader() bool {
+	return l.cfg.Disabled || l.isLeader.Load()
+}
+
+// shutdown останавливает продление аренды и освобождает её, чтобы другая реплика забрала её сразу
+func (l *leader) shutdown(ctx context.Context) error {
+	if !l.started.Load() {
+		return nil
+	}
+
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+
+	if !l.setLeader(false) {
+		return nil
+	}
+
+	return l.r.Release(ctx, schedulerLease, l.holder)
+}
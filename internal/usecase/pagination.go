@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"backend/internal/models"
+)
+
+// campaignsCursor - содержимое курсора списка РК. Сортировка сохраняется,
+// чтобы курсор нельзя было применить к списку с другим порядком
+type campaignsCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	Id    string `json:"id"`
+}
+
+func encodeCampaignsCursor(item *models.CampaignListItem, p models.CampaignsPagination) (res string, err error) {
+	c := campaignsCursor{
+		Sort: p.Sort,
+		Desc: p.Desc,
+		Id:   item.Id,
+	}
+
+	switch p.Sort {
+	case models.CampaignsSortCreatedAt:
+		c.Value = item.CreatedAt.Format(time.RFC3339Nano)
+	case models.CampaignsSortName:
+		c.Value = item.Name
+	case models.CampaignsSortTotalSpend:
+		c.Value = item.TotalSpend.String()
+	case models.CampaignsSortViews7d:
+		c.Value = strconv.Itoa(item.Views7d)
+	default:
+		return res, errors.New("unknown sort field: " + p.Sort)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return res, err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCampaignsCursor(s string, p models.CampaignsPagination) (res *models.CampaignsCursor, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return res, err
+	}
+
+	c := campaignsCursor{}
+
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return res, err
+	}
+
+	if c.Sort != p.Sort || c.Desc != p.Desc {
+		return res, errors.New("cursor sort mismatch")
+	}
+
+	// Значение подставляется в запрос с приведением к типу поля сортировки,
+	// поэтому проверяем его здесь, чтобы подделанный курсор не дошёл до БД
+	switch c.Sort {
+	case models.CampaignsSortCreatedAt:
+		_, err = time.Parse(time.RFC3339Nano, c.Value)
+	case models.CampaignsSortName:
+	case models.CampaignsSortTotalSpend:
+		_, err = decimal.NewFromString(c.Value)
+	case models.CampaignsSortViews7d:
+		_, err = strconv.Atoi(c.Value)
+	default:
+		err = errors.New("unknown sort field: " + c.Sort)
+	}
+	if err != nil {
+		return res, err
+	}
+
+	return &models.CampaignsCursor{Value: c.Value, Id: c.Id}, nil
+}
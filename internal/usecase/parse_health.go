@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/timmbarton/utils/tracing"
+
+	"backend/internal/models"
+	"backend/pkg/tgads"
+)
+
+const (
+	defaultParseHealthWindow      = 100
+	defaultParseHealthThreshold   = 0.5
+	defaultParseHealthMinSamples  = 10
+	defaultParseFailuresRetention = 30 * 24 * time.Hour
+)
+
+// ParseHealthConfig описывает, когда парсер ads.telegram.org считается сломанным
+type ParseHealthConfig struct {
+	// Window - по скольким последним загрузкам считается доля ошибок разбора
+	Window int
+	// Threshold - доля ошибок разбора, начиная с которой парсер считается сломанным
+	Threshold float64
+	// MinSamples - сколько загрузок нужно, чтобы делать вывод
+	MinSamples int
+	// Retention - сколько хранятся неразобранные страницы. Старые удаляются после обновления статистики
+	Retention time.Duration
+}
+
+// parseHealth считает долю ошибок разбора среди последних загрузок.
+// Ошибки сети и удалённые РК не учитываются: они не говорят о смене вёрстки
+type parseHealth struct {
+	mu  sync.Mutex
+	cfg ParseHealthConfig
+	// failed - кольцевой буфер результатов последних загрузок
+	failed    []bool
+	next      int
+	samples   int
+	failures  int
+	healthy   bool
+	changedAt *time.Time
+}
+
+func newParseHealth(cfg ParseHealthConfig) *parseHealth {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultParseHealthWindow
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultParseHealthThreshold
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaultParseHealthMinSamples
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultParseFailuresRetention
+	}
+
+	return &parseHealth{
+		cfg:     cfg,
+		failed:  make([]bool, cfg.Window),
+		healthy: true,
+	}
+}
+
+func (h *parseHealth) record(failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.samples == len(h.failed) {
+		if h.failed[h.next] {
+			h.failures--
+		}
+	} else {
+		h.samples++
+	}
+
+	h.failed[h.next] = failed
+	h.next = (h.next + 1) % len(h.failed)
+
+	if failed {
+		h.failures++
+	}
+
+	if h.samples < h.cfg.MinSamples {
+		return
+	}
+
+	rate := float64(h.failures) / float64(h.samples)
+	healthy := rate < h.cfg.Threshold
+
+	if healthy == h.healthy {
+		return
+	}
+
+	now := time.Now()
+	h.healthy, h.changedAt = healthy, &now
+
+	if healthy {
+		log.Printf("tgads parser recovered: %d of last %d pages failed to parse", h.failures, h.samples)
+	} else {
+		log.Printf("tgads parser is broken: %d of last %d pages failed to parse, layout probably changed", h.failures, h.samples)
+	}
+}
+
+func (h *parseHealth) snapshot() (res models.ScraperHealth) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res = models.ScraperHealth{
+		Healthy:   h.healthy,
+		Threshold: h.cfg.Threshold,
+		Failures:  h.failures,
+		Samples:   h.samples,
+		ChangedAt: h.changedAt,
+	}
+
+	if h.samples > 0 {
+		res.FailureRate = float64(h.failures) / float64(h.samples)
+	}
+
+	return res
+}
+
+// GetScraperHealth возвращает долю ошибок разбора страниц в загрузках этой реплики.
+// Счётчики в памяти, поэтому реплика, которая не загружала РК (например, не лидер без запросов), отвечает healthy с samples = 0
+func (uc *useCase) GetScraperHealth(ctx context.Context) (res models.ScraperHealth, err error) {
+	_, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	return uc.parseHealth.snapshot(), nil
+}
+
+// getCampaign загружает страницу РК, учитывая результат разбора в parseHealth.
+// Ссылка проверяется до загрузки, поэтому у сохранённой страницы всегда есть id РК
+func (uc *useCase) getCampaign(ctx context.Context, link string) (res tgads.Campaign, err error) {
+	id, err := uc.tgads.CampaignId(link)
+	if err != nil {
+		return res, err
+	}
+
+	res, err = uc.tgads.GetCampaign(ctx, link)
+
+	uc.checkParse(ctx, id, err)
+
+	return res, err
+}
+
+// checkParse учитывает результат загрузки в parseHealth и сохраняет страницу или таблицу, которую не удалось разобрать
+func (uc *useCase) checkParse(ctx context.Context, campaignId string, err error) {
+	switch {
+	case err == nil:
+		uc.parseHealth.record(false)
+		return
+	case errors.Is(err, tgads.ErrLayoutChanged):
+		uc.parseHealth.record(true)
+	default:
+		return
+	}
+
+	layoutErr := (*tgads.LayoutError)(nil)
+	if !errors.As(err, &layoutErr) {
+		return
+	}
+
+	err = uc.r.ParseFailures.Create(context.WithoutCancel(ctx), models.ParseFailure{
+		CampaignId: campaignId,
+		Reason:     layoutErr.Reason,
+		Page:       layoutErr.Page,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// deleteOldParseFailures удаляет неразобранные страницы старше Retention
+func (uc *useCase) deleteOldParseFailures(ctx context.Context) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err := uc.r.ParseFailures.DeleteOld(ctx, time.Now().Add(-uc.parseHealth.cfg.Retention))
+	if err != nil {
+		log.Println(err)
+	}
+}
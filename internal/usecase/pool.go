@@ -0,0 +1,31 @@
+package usecase
+
+import "sync"
+
+// forEach обрабатывает items пулом из workers горутин и ждёт завершения
+func forEach[T any](workers int, items []T, fn func(item T)) {
+	ch := make(chan T)
+	wg := &sync.WaitGroup{}
+
+	wg.Add(workers)
+
+	go func() {
+		for _, item := range items {
+			ch <- item
+		}
+
+		close(ch)
+	}()
+
+	for range workers {
+		go func() {
+			defer wg.Done()
+
+			for item := range ch {
+				fn(item)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
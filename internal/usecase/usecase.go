@@ -2,10 +2,15 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
-	"sync"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/timmbarton/layout/lifecycle"
 	"github.com/timmbarton/utils/tracing"
 	"github.com/timmbarton/utils/types/dates"
@@ -15,47 +20,111 @@ import (
 	"backend/internal/models"
 	"backend/internal/repository"
 	"backend/pkg/coingecko"
+	"backend/pkg/errlist"
 	"backend/pkg/tgads"
 )
 
+var (
+	thousand = decimal.NewFromInt(1000)
+	hundred  = decimal.NewFromInt(100)
+)
+
 type UseCase interface {
 	lifecycle.Lifecycle
 
-	CreateCampaign(ctx context.Context, req CreateCampaignRequest) error
-	FetchCampaigns(ctx context.Context) (res []*models.Campaign, err error)
+	CreateCampaign(ctx context.Context, req CreateCampaignRequest) (res CreateCampaignResponse, err error)
+	CreateCampaignsBulk(ctx context.Context, req CreateCampaignsBulkRequest) (res CreateCampaignsBulkResponse, err error)
+	FetchCampaigns(ctx context.Context, req FetchCampaignsRequest) (res models.CampaignsPage, err error)
+	GetCampaign(ctx context.Context, id string) (res models.CampaignDetails, err error)
+	FetchCampaignRevisions(ctx context.Context, id string) (res []*models.CampaignRevision, err error)
+	UpdateCampaign(ctx context.Context, req UpdateCampaignRequest) (res models.CampaignDetails, err error)
+	ArchiveCampaign(ctx context.Context, id string) error
+	DeleteCampaign(ctx context.Context, id string) error
+	AddCampaignTags(ctx context.Context, req AddCampaignTagsRequest) error
+	RemoveCampaignTag(ctx context.Context, id string, tag string) error
+	FetchTags(ctx context.Context) (res []*models.Tag, err error)
+
+	CreateFolder(ctx context.Context, req CreateFolderRequest) (res models.Folder, err error)
+	FetchFolders(ctx context.Context) (res []*models.Folder, err error)
+	DeleteFolder(ctx context.Context, id int64) error
+	SetCampaignFolder(ctx context.Context, req SetCampaignFolderRequest) error
+	FetchStats(ctx context.Context, req FetchStatsRequest) (res []*models.StatsUSD, err error)
+	FetchHourlyStats(ctx context.Context, req FetchStatsRequest) (res []*models.HourlyStats, err error)
+
+	StartRefresh(ctx context.Context, req StartRefreshRequest) (res models.Job, err error)
+	GetJob(ctx context.Context, id string) (res models.Job, err error)
+	FetchJobs(ctx context.Context, req FetchJobsRequest) (res []*models.Job, err error)
+
+	GetScraperHealth(ctx context.Context) (res models.ScraperHealth, err error)
 
 	RefreshStats()
 }
 
 type Config struct {
 	RefreshStatsLoadingWorkersCount int `validate:"min=1,max=10"`
+	// HourlyStatsEnabled включает загрузку почасовой статистики при обновлении
+	HourlyStatsEnabled bool
+	RefreshStats       JobConfig
+	LoadRates          JobConfig
+	Leader             LeaderConfig
+	ParseHealth        ParseHealthConfig
+}
+
+// JobConfig описывает расписание задачи в формате cron (минуты, часы, дни, месяцы, дни недели).
+// Пустое расписание заменяется расписанием по умолчанию
+type JobConfig struct {
+	Schedule string
+	Disabled bool
 }
 
+const (
+	defaultRefreshStatsSchedule = "55 * * * *"
+	defaultLoadRatesSchedule    = "45 * * * *"
+)
+
 func New(cfg Config, r *repository.Repositories, tgads *tgads.Client, cg *coingecko.Client) UseCase {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &useCase{
-		cfg:   cfg,
-		r:     r,
-		tgads: tgads,
-		cg:    cg,
-		c:     cron.New(),
+		ctx:         ctx,
+		cancel:      cancel,
+		cfg:         cfg,
+		r:           r,
+		tgads:       tgads,
+		cg:          cg,
+		c:           cron.New(),
+		jobs:        newJobs(),
+		leader:      newLeader(cfg.Leader, r.Leases),
+		parseHealth: newParseHealth(cfg.ParseHealth),
 	}
 }
 
 type useCase struct {
-	cfg   Config
-	r     *repository.Repositories
-	tgads *tgads.Client
-	cg    *coingecko.Client
-	c     *cron.Cron
+	// ctx - корневой контекст фоновых задач, отменяется при остановке
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running running
+
+	cfg    Config
+	r      *repository.Repositories
+	tgads  *tgads.Client
+	cg     *coingecko.Client
+	c      *cron.Cron
+	jobs   *jobs
+	leader *leader
+	// parseHealth - доля ошибок разбора в загрузках этой реплики
+	parseHealth *parseHealth
 }
 
 func (uc *useCase) Start(_ context.Context) error {
-	_, err := uc.c.AddFunc("45 * * * *", uc.LoadRates)
+	uc.leader.start()
+
+	err := uc.addJob("load rates", uc.cfg.LoadRates, defaultLoadRatesSchedule, uc.LoadRates)
 	if err != nil {
 		return err
 	}
 
-	_, err = uc.c.AddFunc("55 * * * *", uc.RefreshStats)
+	err = uc.addJob("refresh stats", uc.cfg.RefreshStats, defaultRefreshStatsSchedule, uc.RefreshStats)
 	if err != nil {
 		return err
 	}
@@ -64,124 +133,808 @@ func (uc *useCase) Start(_ context.Context) error {
 
 	return nil
 }
-func (uc *useCase) Stop(_ context.Context) error {
-	uc.c.Stop()
+
+// addJob ставит задачу в cron, если она не отключена в конфиге
+func (uc *useCase) addJob(name string, cfg JobConfig, defaultSchedule string, fn func()) error {
+	if cfg.Disabled {
+		log.Printf("%s job is disabled", name)
+		return nil
+	}
+
+	schedule := cfg.Schedule
+	if schedule == "" {
+		schedule = defaultSchedule
+	}
+
+	_, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return fmt.Errorf("invalid %s schedule %q: %w", name, schedule, err)
+	}
+
+	_, err = uc.c.AddFunc(schedule, func() {
+		// Задачи по расписанию выполняет только одна реплика
+		if !uc.leader.IsLeader() {
+			return
+		}
+
+		fn()
+	})
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
+
+// Stop перестаёт запускать задачи и ждёт выполняющиеся до дедлайна ctx.
+// Не успевшие завершиться задачи отменяются и сохраняются как прерванные
+func (uc *useCase) Stop(ctx context.Context) error {
+	cronCtx := uc.c.Stop()
+
+	err := uc.waitJobs(ctx)
+	if err == nil {
+		// Задачи cron уже завершились вместе с отслеживаемыми задачами
+		<-cronCtx.Done()
+	}
+
+	return errors.Join(err, uc.leader.shutdown(context.WithoutCancel(ctx)))
+}
 func (uc *useCase) GetName() string { return "Use Case" }
 
+// RefreshStats обновляет статистику всех неархивных РК. Запускается по расписанию
 func (uc *useCase) RefreshStats() {
-	ctx := context.Background()
+	if !uc.running.add() {
+		return
+	}
+	defer uc.running.done()
+
+	ctx, cancel := uc.leader.fence(uc.ctx)
+	defer cancel()
 
-	cmps, err := uc.r.Campaigns.Fetch(ctx)
+	// Задача создаётся до загрузки списка РК, чтобы ошибка БД тоже попала в историю запусков
+	j, started := uc.startJobIfIdle(ctx, models.JobTypeRefreshStats)
+	if !started {
+		log.Printf("refresh stats job %s is already running", j.id())
+		return
+	}
+
+	cmps, err := uc.r.Campaigns.FetchNotArchived(ctx)
 	if err != nil {
 		log.Println(err)
+		uc.finishJob(ctx, j, err)
+		return
 	}
 
-	ch := make(chan *models.Campaign)
-	wg := &sync.WaitGroup{}
+	j.setTotal(len(cmps))
 
-	wg.Add(uc.cfg.RefreshStatsLoadingWorkersCount)
+	uc.refreshStats(ctx, j, cmps)
 
-	go func() {
-		for _, cmp := range cmps {
-			ch <- cmp
+	uc.deleteOldParseFailures(ctx)
+}
+
+type StartRefreshRequest struct {
+	// CampaignId - РК для обновления. Если пустой, обновляются все неархивные РК
+	CampaignId string
+}
+
+// StartRefresh запускает обновление статистики в фоне и сразу возвращает созданную задачу.
+// Если обновление всех РК уже идёт, возвращается текущая задача
+func (uc *useCase) StartRefresh(ctx context.Context, req StartRefreshRequest) (res models.Job, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	if !uc.running.add() {
+		return res, errlist.ErrShuttingDown
+	}
+
+	// Если задача не ушла в фон, её место в running освобождается здесь
+	launched := false
+	defer func() {
+		if !launched {
+			uc.running.done()
+		}
+	}()
+
+	j := (*job)(nil)
+	cmps := make([]*models.Campaign, 0)
+
+	if req.CampaignId == "" {
+		started := false
+
+		j, started = uc.startJobIfIdle(ctx, models.JobTypeRefreshStats)
+		if !started {
+			return j.snapshot(), nil
 		}
 
-		close(ch)
+		cmps, err = uc.r.Campaigns.FetchNotArchived(ctx)
+		if err != nil {
+			uc.finishJob(ctx, j, err)
+			return res, err
+		}
+
+		j.setTotal(len(cmps))
+	} else {
+		c, err := uc.r.Campaigns.Get(ctx, req.CampaignId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return res, errlist.ErrCampaignNotFound
+		}
+		if err != nil {
+			return res, err
+		}
+
+		cmps = append(cmps, &c)
+		j = uc.startJob(ctx, models.JobTypeRefreshCampaign, len(cmps))
+	}
+
+	launched = true
+
+	go func() {
+		defer uc.running.done()
+
+		uc.refreshStats(uc.ctx, j, cmps)
 	}()
 
-	for range uc.cfg.RefreshStatsLoadingWorkersCount {
-		go func() {
-			defer wg.Done()
+	return j.snapshot(), nil
+}
+
+// GetJob возвращает состояние задачи. Результаты по РК есть только у задач, запущенных этим процессом
+func (uc *useCase) GetJob(ctx context.Context, id string) (res models.Job, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	j, ok := uc.jobs.get(id)
+	if ok {
+		return j.snapshot(), nil
+	}
+
+	err = uuid.Validate(id)
+	if err != nil {
+		return res, errlist.ErrJobNotFound
+	}
+
+	res, err = uc.r.Jobs.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, errlist.ErrJobNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+type FetchJobsRequest struct {
+	Limit int `validate:"omitempty,min=1,max=200"`
+}
+
+const defaultJobsLimit = 50
+
+// FetchJobs возвращает историю запусков задач, от новых к старым
+func (uc *useCase) FetchJobs(ctx context.Context, req FetchJobsRequest) (res []*models.Job, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	if req.Limit == 0 {
+		req.Limit = defaultJobsLimit
+	}
+
+	res, err = uc.r.Jobs.Fetch(ctx, req.Limit)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// refreshStats обновляет статистику РК пулом воркеров, записывая результаты в задачу
+func (uc *useCase) refreshStats(ctx context.Context, j *job, cmps []*models.Campaign) {
+	forEach(uc.cfg.RefreshStatsLoadingWorkersCount, cmps, func(cmp *models.Campaign) {
+		// После остановки оставшиеся РК пропускаются
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := uc.refreshCampaign(ctx, cmp)
+		if err != nil {
+			log.Printf("refresh campaign %s (temporary: %t): %v", cmp.Id, tgads.IsTemporary(err), err)
+		}
+
+		uc.campaignDone(ctx, j, cmp.Id, err)
+	})
+
+	uc.logLimiterStats()
+
+	uc.finishJob(ctx, j, nil)
+}
+
+// logLimiterStats пишет в лог, сколько запросов к ads.telegram.org ждали ограничителя
+func (uc *useCase) logLimiterStats() {
+	stats := uc.tgads.LimiterStats()
+
+	log.Printf(
+		"tgads limiter: throttled %d of %d requests, waited %s total, last wait %s",
+		stats.Throttled, stats.Requests, stats.Waited, stats.LastWait,
+	)
+}
+
+// refreshCampaign заново скачивает данные и статистику одной РК
+func (uc *useCase) refreshCampaign(ctx context.Context, cmp *models.Campaign) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	ctx = tgads.WithProxySession(ctx)
+
+	rawCmp, err := uc.getCampaign(ctx, uc.tgads.CampaignShareLink(cmp.Id))
+	if err != nil {
+		return err
+	}
+
+	err = uc.saveCampaign(ctx, newCampaign(cmp.Name, rawCmp))
+	if err != nil {
+		return err
+	}
 
-			stats := make([]*tgads.Stats, 0)
-			rawCmp := tgads.Campaign{}
+	_, err = uc.importStats(ctx, rawCmp)
+	if err != nil {
+		return err
+	}
 
-			for cmp := range ch {
-				rawCmp, err = uc.tgads.GetCampaign(ctx, tgads.GetCampaignShareLink(cmp.Id))
-				if err != nil {
-					log.Println(err)
-					continue
-				}
+	return nil
+}
 
-				stats, err = uc.tgads.GetStats(ctx, rawCmp.StatsCSVLink, rawCmp.BudgetCSVLink)
-				if err != nil {
-					log.Println(err)
-					continue
-				}
+// importStats скачивает и сохраняет статистику РК, возвращает количество загруженных дней
+func (uc *useCase) importStats(ctx context.Context, raw tgads.Campaign) (days int, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
 
-				err = uc.r.Stats.Create(ctx, cmp.Id, stats)
-				if err != nil {
-					log.Println(err)
-					continue
-				}
-			}
-		}()
+	stats, err := uc.tgads.GetStats(ctx, raw.StatsCSVLink, raw.BudgetCSVLink)
+	uc.checkParse(ctx, raw.Id, err)
+	if err != nil {
+		return days, err
 	}
 
-	wg.Wait()
+	err = uc.r.Stats.Create(ctx, raw.Id, stats)
+	if err != nil {
+		return days, err
+	}
+
+	days = len(stats)
+
+	if !uc.cfg.HourlyStatsEnabled {
+		return days, nil
+	}
+
+	stats, err = uc.tgads.GetHourlyStats(ctx, raw.StatsCSVLink, raw.BudgetCSVLink)
+	uc.checkParse(ctx, raw.Id, err)
+	if err != nil {
+		return days, err
+	}
+
+	err = uc.r.Stats.CreateHourly(ctx, raw.Id, stats)
+	if err != nil {
+		return days, err
+	}
+
+	return days, nil
 }
 
 // LoadRates подгружает курс TON к USD
 func (uc *useCase) LoadRates() {
-	ctx := context.Background()
+	if !uc.running.add() {
+		return
+	}
+	defer uc.running.done()
 
+	ctx, cancel := uc.leader.fence(uc.ctx)
+	defer cancel()
+
+	j := uc.startJob(ctx, models.JobTypeLoadRates, 0)
+
+	err := uc.loadRates(ctx)
+	if err != nil {
+		log.Println(err)
+	}
+
+	uc.finishJob(ctx, j, err)
+}
+
+// loadRates сохраняет курс TON к USD за вчера и сегодня
+func (uc *useCase) loadRates(ctx context.Context) error {
 	yesterdayDate := dates.Date(time.Now().AddDate(0, 0, -1))
 	todayDate := dates.Date(time.Now())
 
 	yesterdayRate, err := uc.cg.GetTonRate(ctx, yesterdayDate)
 	if err != nil {
-		log.Println(err)
-		return
+		return err
 	}
 
 	err = uc.r.Rates.Create(ctx, yesterdayDate, yesterdayRate)
 	if err != nil {
-		log.Println(err)
-		return
+		return err
 	}
 
 	todayRate, err := uc.cg.GetTonRate(ctx, todayDate)
 	if err != nil {
-		log.Println(err)
-		return
+		return err
 	}
 
 	err = uc.r.Rates.Create(ctx, todayDate, todayRate)
 	if err != nil {
-		log.Println(err)
-		return
+		return err
 	}
+
+	return nil
 }
 
 type CreateCampaignRequest struct {
 	Link string `json:"link" validate:"required"`
 	Name string `json:"name"`
+	// Wait - загрузить историческую статистику до ответа, иначе она загружается в фоне
+	Wait bool `json:"-"`
+}
+
+type CreateCampaignResponse struct {
+	Id string `json:"id"`
+	// ImportedDays - количество загруженных дней статистики, если Wait = true
+	ImportedDays *int `json:"imported_days,omitempty"`
+	// ImportError - ошибка загрузки статистики, если Wait = true. РК при этом уже добавлена,
+	// статистика загрузится при следующем обновлении
+	ImportError *string `json:"import_error,omitempty"`
+	// Job - фоновая задача загрузки статистики, если Wait = false
+	Job *models.Job `json:"job,omitempty"`
+}
+
+// CreateCampaign добавляет РК и сразу загружает всю её статистику
+func (uc *useCase) CreateCampaign(ctx context.Context, req CreateCampaignRequest) (res CreateCampaignResponse, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	// Ссылку проверяем до загрузки, чтобы не ходить через прокси по произвольным адресам
+	_, err = uc.tgads.CampaignId(req.Link)
+	if err != nil {
+		return res, errlist.ErrBadRequest
+	}
+
+	// Проверяем до сохранения РК, чтобы при остановке сервиса не добавить РК без статистики
+	if !uc.running.add() {
+		return res, errlist.ErrShuttingDown
+	}
+
+	// Если загрузка не ушла в фон, её место в running освобождается здесь
+	launched := false
+	defer func() {
+		if !launched {
+			uc.running.done()
+		}
+	}()
+
+	ctx = tgads.WithProxySession(ctx)
+
+	raw, err := uc.getCampaign(ctx, req.Link)
+	if errors.Is(err, tgads.ErrCampaignNotFound) {
+		return res, errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	c := newCampaign(req.Name, raw)
+
+	created, err := uc.r.Campaigns.Create(ctx, c)
+	if err != nil {
+		return res, err
+	}
+
+	// Существующая РК не меняется, поэтому и версию объявления записывать нельзя
+	if created {
+		err = uc.r.Revisions.Create(ctx, c)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	res.Id = c.Id
+
+	if req.Wait {
+		days, err := uc.importStats(ctx, raw)
+		if err != nil {
+			log.Printf("campaign %s is created, but stats import failed: %s", c.Id, err)
+
+			msg := err.Error()
+			res.ImportError = &msg
+
+			return res, nil
+		}
+
+		res.ImportedDays = &days
+
+		return res, nil
+	}
+
+	j := uc.startJob(ctx, models.JobTypeRefreshCampaign, 1)
+
+	launched = true
+
+	go func() {
+		defer uc.running.done()
+
+		ctx := uc.ctx
+
+		_, err := uc.importStats(tgads.WithProxySession(ctx), raw)
+		if err != nil {
+			log.Println(err)
+		}
+
+		uc.campaignDone(ctx, j, raw.Id, err)
+		uc.finishJob(ctx, j, nil)
+	}()
+
+	snapshot := j.snapshot()
+	res.Job = &snapshot
+
+	return res, nil
 }
 
-func (uc *useCase) CreateCampaign(ctx context.Context, req CreateCampaignRequest) error {
+// saveCampaign обновляет данные РК и сохраняет версию объявления, если она изменилась
+func (uc *useCase) saveCampaign(ctx context.Context, c models.Campaign) error {
 	ctx, span := tracing.NewSpan(ctx)
 	defer span.End()
 
-	raw, err := uc.tgads.GetCampaign(ctx, req.Link)
+	err := uc.r.Campaigns.Update(ctx, c)
 	if err != nil {
 		return err
 	}
 
-	c := models.Campaign{
+	err = uc.r.Revisions.Create(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newCampaign собирает РК из данных, полученных со страницы статистики
+func newCampaign(name string, raw tgads.Campaign) models.Campaign {
+	return models.Campaign{
 		Id:            raw.Id,
-		Name:          req.Name,
+		Name:          name,
 		StatsCSVLink:  raw.StatsCSVLink,
 		BudgetCSVLink: raw.BudgetCSVLink,
 		Text:          raw.Text,
 		ButtonText:    raw.ButtonText,
 		Link:          raw.Link,
 		Active:        raw.Active,
+		Title:         raw.Title,
+		CPMBid:        raw.CPMBid,
+		Budget:        raw.Budget,
+		DailyBudget:   raw.DailyBudget,
+		Channels:      raw.Channels,
+		Languages:     raw.Languages,
+		Topics:        raw.Topics,
+		AdCreatedAt:   raw.CreatedAt,
+	}
+}
+
+type FetchCampaignsRequest struct {
+	Tag      *string `validate:"omitempty,max=64"`
+	FolderId *int64  `validate:"omitempty,min=1"`
+	Active   *bool
+	Query    *string `validate:"omitempty,max=255"`
+	Sort     string  `validate:"omitempty,oneof=created_at name total_spend views_7d"`
+	Order    string  `validate:"omitempty,oneof=asc desc"`
+	Limit    int     `validate:"omitempty,min=1,max=200"`
+	Cursor   string
+}
+
+const defaultCampaignsLimit = 50
+
+// FetchCampaigns возвращает страницу списка РК. Следующая страница запрашивается по NextCursor
+func (uc *useCase) FetchCampaigns(ctx context.Context, req FetchCampaignsRequest) (res models.CampaignsPage, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	f := models.CampaignsFilter{
+		Tag:      req.Tag,
+		FolderId: req.FolderId,
+		Active:   req.Active,
+		Query:    req.Query,
+	}
+
+	p := models.CampaignsPagination{
+		Sort:  req.Sort,
+		Desc:  req.Order != "asc",
+		Limit: req.Limit,
+	}
+
+	if p.Sort == "" {
+		p.Sort = models.CampaignsSortCreatedAt
+	}
+
+	if p.Limit == 0 {
+		p.Limit = defaultCampaignsLimit
+	}
+
+	if req.Cursor != "" {
+		p.After, err = decodeCampaignsCursor(req.Cursor, p)
+		if err != nil {
+			return res, errlist.ErrBadRequest
+		}
+	}
+
+	// Запрашиваем на одну РК больше, чтобы понять, есть ли следующая страница
+	limit := p.Limit
+	p.Limit++
+
+	res.Items, err = uc.r.Campaigns.Fetch(ctx, f, p)
+	if err != nil {
+		return res, err
+	}
+
+	if len(res.Items) > limit {
+		res.Items = res.Items[:limit]
+
+		cursor, err := encodeCampaignsCursor(res.Items[limit-1], p)
+		if err != nil {
+			return res, err
+		}
+
+		res.NextCursor = &cursor
+	}
+
+	return res, nil
+}
+
+// GetCampaign возвращает РК вместе с суммарной статистикой за всё время
+func (uc *useCase) GetCampaign(ctx context.Context, id string) (res models.CampaignDetails, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res.Campaign, err = uc.r.Campaigns.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	res.Totals, err = uc.r.Stats.FetchTotals(ctx, id)
+	if err != nil {
+		return res, err
+	}
+
+	if res.Totals.Views > 0 {
+		views := decimal.NewFromInt(int64(res.Totals.Views))
+
+		res.Totals.Cpm = res.Totals.Spend.Mul(thousand).Div(views)
+		res.Totals.Ctr = decimal.NewFromInt(int64(res.Totals.Clicks)).Mul(hundred).Div(views)
+	}
+
+	if res.Budget != nil {
+		remaining := res.Budget.Sub(res.Totals.Spend)
+		res.RemainingBudget = &remaining
+	}
+
+	return res, nil
+}
+
+// FetchCampaignRevisions возвращает историю изменений объявления РК, от новых к старым
+func (uc *useCase) FetchCampaignRevisions(ctx context.Context, id string) (res []*models.CampaignRevision, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err = uc.r.Revisions.Fetch(ctx, id)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+type UpdateCampaignRequest struct {
+	Id     string  `json:"-" validate:"required"`
+	Name   *string `json:"name" validate:"omitempty,max=255"`
+	Notes  *string `json:"notes" validate:"omitempty,max=10000"`
+	Client *string `json:"client" validate:"omitempty,max=255"`
+}
+
+// UpdateCampaign меняет название, заметки и клиента РК. Непереданные поля не меняются
+func (uc *useCase) UpdateCampaign(ctx context.Context, req UpdateCampaignRequest) (res models.CampaignDetails, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err = uc.r.Campaigns.UpdateInfo(ctx, req.Id, req.Name, req.Notes, req.Client)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+
+	return uc.GetCampaign(ctx, req.Id)
+}
+
+// ArchiveCampaign отключает обновление статистики РК, сохраняя собранные данные
+func (uc *useCase) ArchiveCampaign(ctx context.Context, id string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err := uc.r.Campaigns.Archive(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCampaign удаляет РК вместе со всей собранной статистикой
+func (uc *useCase) DeleteCampaign(ctx context.Context, id string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err := uc.r.Campaigns.Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type AddCampaignTagsRequest struct {
+	CampaignId string   `json:"-" validate:"required"`
+	Tags       []string `json:"tags" validate:"required,min=1,max=50,dive,required,max=64"`
+}
+
+// AddCampaignTags помечает РК тегами, создавая новые теги при необходимости
+func (uc *useCase) AddCampaignTags(ctx context.Context, req AddCampaignTagsRequest) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := uc.r.Campaigns.Get(ctx, req.CampaignId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return errlist.ErrBadRequest
+	}
+
+	err = uc.r.Tags.Add(ctx, req.CampaignId, tags)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveCampaignTag снимает тег с РК
+func (uc *useCase) RemoveCampaignTag(ctx context.Context, id string, tag string) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	_, err := uc.r.Campaigns.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrCampaignNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = uc.r.Tags.Remove(ctx, id, tag)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrTagNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (uc *useCase) FetchTags(ctx context.Context) (res []*models.Tag, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err = uc.r.Tags.Fetch(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+type CreateFolderRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// CreateFolder создаёт папку РК. Если папка с таким названием уже есть, возвращает её
+func (uc *useCase) CreateFolder(ctx context.Context, req CreateFolderRequest) (res models.Folder, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return res, errlist.ErrBadRequest
+	}
+
+	res, err = uc.r.Folders.Create(ctx, name)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (uc *useCase) FetchFolders(ctx context.Context) (res []*models.Folder, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res, err = uc.r.Folders.Fetch(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// DeleteFolder удаляет папку. РК из неё не удаляются, а остаются без папки
+func (uc *useCase) DeleteFolder(ctx context.Context, id int64) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	err := uc.r.Folders.Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrFolderNotFound
+	}
+	if err != nil {
+		return err
 	}
 
-	err = uc.r.Campaigns.Create(ctx, c)
+	return nil
+}
+
+type SetCampaignFolderRequest struct {
+	CampaignId string `json:"-" validate:"required"`
+	// FolderId - папка РК, null - убрать РК из папки
+	FolderId *int64 `json:"folder_id" validate:"omitempty,min=1"`
+}
+
+// SetCampaignFolder перекладывает РК в другую папку или убирает из папки
+func (uc *useCase) SetCampaignFolder(ctx context.Context, req SetCampaignFolderRequest) error {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	if req.FolderId != nil {
+		_, err := uc.r.Folders.Get(ctx, *req.FolderId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errlist.ErrFolderNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err := uc.r.Folders.SetCampaign(ctx, req.CampaignId, req.FolderId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errlist.ErrCampaignNotFound
+	}
 	if err != nil {
 		return err
 	}
@@ -189,14 +942,80 @@ func (uc *useCase) CreateCampaign(ctx context.Context, req CreateCampaignRequest
 	return nil
 }
 
-func (uc *useCase) FetchCampaigns(ctx context.Context) (res []*models.Campaign, err error) {
+type FetchStatsRequest struct {
+	CampaignId string `validate:"required"`
+	From       dates.Date
+	To         dates.Date
+}
+
+// FetchStats возвращает статистику РК по дням за период [From, To]
+// с пересчётом расходов в USD по курсу TON на ту же дату
+func (uc *useCase) FetchStats(ctx context.Context, req FetchStatsRequest) (res []*models.StatsUSD, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer span.End()
+
+	res = make([]*models.StatsUSD, 0)
+
+	stats, err := uc.r.Stats.Fetch(ctx, req.CampaignId, req.From, req.To)
+	if err != nil {
+		return res, err
+	}
+
+	rates, err := uc.r.Rates.Fetch(ctx, req.From, req.To)
+	if err != nil {
+		return res, err
+	}
+
+	ratesByDate := make(map[string]decimal.Decimal, len(rates))
+	for _, rate := range rates {
+		ratesByDate[dateKey(rate.Date)] = rate.Rate
+	}
+
+	for _, s := range stats {
+		item := &models.StatsUSD{Stats: *s}
+
+		rate, ok := ratesByDate[dateKey(s.Date)]
+		if ok {
+			spendUsd := s.Spend.Mul(rate)
+			cpmUsd := s.Cpm.Mul(rate)
+
+			item.Rate = &rate
+			item.SpendUsd = &spendUsd
+			item.CpmUsd = &cpmUsd
+		} else {
+			item.RateMissing = true
+		}
+
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+// FetchHourlyStats возвращает почасовую статистику РК за дни [From, To]
+func (uc *useCase) FetchHourlyStats(ctx context.Context, req FetchStatsRequest) (res []*models.HourlyStats, err error) {
 	ctx, span := tracing.NewSpan(ctx)
 	defer span.End()
 
-	res, err = uc.r.Campaigns.Fetch(ctx)
+	from := dayStart(time.Time(req.From))
+	to := dayStart(time.Time(req.To)).AddDate(0, 0, 1)
+
+	res, err = uc.r.Stats.FetchHourly(ctx, req.CampaignId, from, to)
 	if err != nil {
 		return res, err
 	}
 
 	return res, nil
 }
+
+// dayStart возвращает начало календарного дня t в UTC. Truncate тут не подходит:
+// он округляет по UTC, и дата в другом поясе может сдвинуться на день
+func dayStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func dateKey(d dates.Date) string {
+	return time.Time(d).Format(time.DateOnly)
+}
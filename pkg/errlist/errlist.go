@@ -3,5 +3,10 @@ package errlist
 import "github.com/timmbarton/errors"
 
 var (
-	ErrBadRequest = errs.New(errs.ErrCodeBadRequest, 10_0001, "bad request")
+	ErrBadRequest       = errs.New(errs.ErrCodeBadRequest, 10_0001, "bad request")
+	ErrCampaignNotFound = errs.New(errs.ErrCodeNotFound, 10_0002, "campaign not found")
+	ErrJobNotFound      = errs.New(errs.ErrCodeNotFound, 10_0003, "job not found")
+	ErrShuttingDown     = errs.New(errs.ErrCodeServiceUnavailable, 10_0004, "service is shutting down")
+	ErrTagNotFound      = errs.New(errs.ErrCodeNotFound, 10_0005, "tag not found")
+	ErrFolderNotFound   = errs.New(errs.ErrCodeNotFound, 10_0006, "folder not found")
 )
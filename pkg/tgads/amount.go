@@ -0,0 +1,26 @@
+package tgads
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// decimalCommaRegex - число с запятой в роли десятичного разделителя, например "0,5"
+var decimalCommaRegex = regexp.MustCompile(`^\d+,\d{1,2}$`)
+
+// parseDecimal разбирает число из настроек РК или таблицы бюджета. Пробелы убираются.
+// Запятая считается десятичным разделителем, только если она одна и после неё 1-2 цифры, как в "0,5".
+// Иначе это разделитель тысяч: "1,250", "1,250.50"
+func parseDecimal(s string) (res decimal.Decimal, err error) {
+	s = strings.Join(strings.Fields(s), "")
+
+	if decimalCommaRegex.MatchString(s) {
+		s = strings.Replace(s, ",", ".", 1)
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	return decimal.NewFromString(s)
+}
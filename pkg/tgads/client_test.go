@@ -0,0 +1,480 @@
+package tgads
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// newTestClient поднимает httptest-сервер, который отдаёт страницы РК и таблицы из testdata
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/stats/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := os.ReadFile(filepath.Join("testdata", r.PathValue("id")+".html"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set(headerContentType, "text/html; charset=utf-8")
+		_, _ = w.Write(body)
+	})
+
+	mux.HandleFunc("/csv", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		body, err := os.ReadFile(filepath.Join("testdata", q.Get("campaign")+"_"+q.Get("type")+".tsv"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if q.Get("period") != periodDay {
+			t.Errorf("period = %q, want %q", q.Get("period"), periodDay)
+		}
+
+		w.Header().Set(headerContentType, contentTypeCsv)
+		_, _ = w.Write(body)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := New(Config{
+		BaseURL:           srv.URL,
+		RetryDisabled:     true,
+		RateLimitDisabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestClient_CampaignId(t *testing.T) {
+	c, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := c.CampaignId(c.CampaignShareLink("AbC123"))
+	if err != nil || id != "AbC123" {
+		t.Fatalf("CampaignId() = %q, %v, want AbC123", id, err)
+	}
+
+	for _, link := range []string{
+		"",
+		"https://ads.telegram.org/stats/",
+		"https://ads.telegram.org/stats/AbC123/",
+		"http://ads.telegram.org/stats/AbC123",
+		"https://example.com/stats/AbC123",
+	} {
+		_, err = c.CampaignId(link)
+		if !errors.Is(err, ErrInvalidLink) {
+			t.Errorf("CampaignId(%q) error = %v, want ErrInvalidLink", link, err)
+		}
+	}
+}
+
+func TestClient_GetCampaign(t *testing.T) {
+	c := newTestClient(t)
+
+	amount := func(s string) *decimal.Decimal {
+		d := decimal.RequireFromString(s)
+		return &d
+	}
+
+	date := func(year int, month time.Month, day, hour, min int) *time.Time {
+		t := time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		id      string
+		want    Campaign
+		wantErr error
+	}{
+		{
+			id: "active",
+			want: Campaign{
+				Text:        "Best channel about <b>Go</b>",
+				ButtonText:  "Subscribe",
+				Link:        "https://t.me/examplechannel",
+				Active:      true,
+				Title:       "Go channel promo",
+				CPMBid:      amount("1.25"),
+				Budget:      amount("1250.50"),
+				DailyBudget: amount("100"),
+				Channels:    []string{"@golang_news", "@gophers"},
+				Languages:   []string{"English", "Russian"},
+				Topics:      []string{"Technologies"},
+				CreatedAt:   date(2026, time.September, 14, 18, 30),
+			},
+		},
+		{
+			id: "stopped",
+			want: Campaign{
+				Text:        "Old ad text",
+				ButtonText:  "Open",
+				Link:        "https://t.me/stoppedchannel",
+				Active:      false,
+				Title:       "Old promo",
+				CPMBid:      amount("0.5"),
+				Budget:      amount("20"),
+				DailyBudget: amount("1250"),
+				Channels:    []string{},
+				Languages:   []string{},
+				Topics:      []string{},
+				CreatedAt:   date(2026, time.August, 1, 0, 0),
+			},
+		},
+		{
+			id: "unlimited",
+			want: Campaign{
+				Text:       "Old ad text",
+				ButtonText: "Open",
+				Link:       "https://t.me/unlimitedchannel",
+				Active:     false,
+				Title:      "Unlimited promo",
+				Channels:   []string{},
+				Languages:  []string{},
+				Topics:     []string{},
+			},
+		},
+		{
+			id:      "notfound",
+			wantErr: ErrCampaignNotFound,
+		},
+		{
+			id:      "missing",
+			wantErr: ErrCampaignNotFound,
+		},
+		{
+			id:      "broken",
+			wantErr: ErrLayoutChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := c.GetCampaign(context.Background(), c.CampaignShareLink(tt.id))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetCampaign() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.Id = tt.id
+			tt.want.StatsCSVLink = c.baseURL + "/csv?campaign=" + tt.id + "&period=day&type=stats"
+			tt.want.BudgetCSVLink = c.baseURL + "/csv?campaign=" + tt.id + "&period=day&type=budget"
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCampaign() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_GetCampaign_LayoutError(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.GetCampaign(context.Background(), c.CampaignShareLink("broken"))
+
+	layoutErr := (*LayoutError)(nil)
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("GetCampaign() error = %v, want *LayoutError", err)
+	}
+
+	if layoutErr.Reason == "" || len(layoutErr.Page) == 0 {
+		t.Errorf("LayoutError = %q with %d bytes page, want reason and page", layoutErr.Reason, len(layoutErr.Page))
+	}
+}
+
+func TestClient_GetStats(t *testing.T) {
+	c := newTestClient(t)
+
+	day := func(d int) time.Time {
+		return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		id      string
+		want    []*Stats
+		wantErr error
+	}{
+		{
+			id: "active",
+			want: []*Stats{
+				{
+					Datetime: day(1),
+					Views:    1204,
+					Clicks:   31,
+					Actions:  5,
+					Spend:    decimal.RequireFromString("1.5"),
+					CPM:      decimal.RequireFromString("1.5").Mul(thousand).Div(decimal.NewFromInt(1204)),
+				},
+				{
+					Datetime: day(2),
+					Views:    2000,
+					Clicks:   40,
+					Spend:    decimal.NewFromInt(3),
+					CPM:      decimal.RequireFromString("1.5"),
+				},
+			},
+		},
+		{
+			id:   "stopped",
+			want: []*Stats{},
+		},
+		{
+			id: "zeroviews",
+			want: []*Stats{
+				{
+					Datetime: day(1),
+				},
+			},
+		},
+		{
+			id: "mismatched",
+			want: []*Stats{
+				{
+					Datetime: day(1),
+					Views:    100,
+					Clicks:   1,
+					Spend:    decimal.RequireFromString("0.1"),
+					CPM:      decimal.NewFromInt(1),
+				},
+				{
+					Datetime: day(2),
+					Views:    200,
+					Clicks:   2,
+				},
+				{
+					Datetime: day(3),
+					Spend:    decimal.RequireFromString("0.2"),
+					CPM:      decimal.NewFromInt(200),
+				},
+				{
+					Datetime: day(4),
+					Spend:    decimal.RequireFromString("0.3"),
+					CPM:      decimal.NewFromInt(300),
+				},
+			},
+		},
+		{
+			id: "reordered",
+			want: []*Stats{
+				{
+					Datetime: day(5),
+					Views:    1000,
+					Clicks:   15,
+					Actions:  3,
+					Spend:    decimal.RequireFromString("0.7"),
+					CPM:      decimal.RequireFromString("0.7"),
+				},
+			},
+		},
+		{
+			id: "thousands",
+			want: []*Stats{
+				{
+					Datetime: day(6),
+					Views:    1000000,
+					Clicks:   1200,
+					Actions:  40,
+					Spend:    decimal.RequireFromString("1250.50"),
+					CPM:      decimal.RequireFromString("1.2505"),
+				},
+			},
+		},
+		{
+			id:      "nocolumns",
+			wantErr: ErrLayoutChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			raw, err := c.GetCampaign(context.Background(), c.CampaignShareLink(tt.id))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.GetStats(context.Background(), raw.StatsCSVLink, raw.BudgetCSVLink)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetStats() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetStats() returned %d rows, want %d", len(got), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				if !statsEqual(got[i], want) {
+					t.Errorf("GetStats()[%d] = %+v, want %+v", i, *got[i], *want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GetStats_MissingColumn(t *testing.T) {
+	c := newTestClient(t)
+
+	raw, err := c.GetCampaign(context.Background(), c.CampaignShareLink("nocolumns"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.GetStats(context.Background(), raw.StatsCSVLink, raw.BudgetCSVLink)
+
+	columnErr := (*MissingColumnError)(nil)
+	if !errors.As(err, &columnErr) {
+		t.Fatalf("GetStats() error = %v, want *MissingColumnError", err)
+	}
+
+	if columnErr.Table != tableStats || columnErr.Column != columnViews {
+		t.Errorf("MissingColumnError = %s/%s, want %s/%s", columnErr.Table, columnErr.Column, tableStats, columnViews)
+	}
+
+	layoutErr := (*LayoutError)(nil)
+	if !errors.As(err, &layoutErr) || len(layoutErr.Page) == 0 {
+		t.Errorf("GetStats() error = %v, want *LayoutError with the table", err)
+	}
+}
+
+func TestClient_GetStats_NotCSV(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.GetStats(context.Background(), c.CampaignShareLink("active"), c.CampaignShareLink("active"))
+	if !errors.Is(err, ErrNotCSV) {
+		t.Fatalf("GetStats() error = %v, want ErrNotCSV", err)
+	}
+}
+
+func TestClient_RetryConnectionReset(t *testing.T) {
+	attempts := atomic.Int32{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		_ = conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(Config{
+		BaseURL:           srv.URL,
+		RetryCount:        2,
+		RetryWaitTime:     time.Millisecond,
+		RetryMaxWaitTime:  time.Millisecond,
+		RateLimitDisabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.GetCampaign(context.Background(), c.CampaignShareLink("active"))
+	if err == nil {
+		t.Fatal("GetCampaign() error = nil, want connection error")
+	}
+
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("server got %d attempts, want 3", got)
+	}
+}
+
+func TestParseDatetime(t *testing.T) {
+	tests := []struct {
+		format  string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{format: telegramDatetimeFormat, value: "05 Oct 2026", want: time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC)},
+		{format: telegramHourlyDatetimeFormat, value: "05 Oct 2026 13:00 UTC", want: time.Date(2026, time.October, 5, 13, 0, 0, 0, time.UTC)},
+		{format: telegramHourlyDatetimeFormat, value: "05 Oct 2026 13:00 GMT", want: time.Date(2026, time.October, 5, 13, 0, 0, 0, time.UTC)},
+		{format: telegramHourlyDatetimeFormat, value: "05 Oct 2026 13:00 MSK", want: time.Date(2026, time.October, 5, 10, 0, 0, 0, time.UTC)},
+		{format: telegramHourlyDatetimeFormat, value: "05 Oct 2026 13:00 PDT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDatetime(tt.format, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDatetime() = %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDatetime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTemporary(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "rate limited", err: ErrRateLimited, want: true},
+		{name: "5xx", err: &StatusError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "4xx", err: &StatusError{StatusCode: http.StatusNotFound}, want: false},
+		{name: "not found", err: ErrCampaignNotFound, want: false},
+		{name: "layout changed", err: ErrLayoutChanged, want: false},
+		{name: "canceled", err: context.Canceled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTemporary(tt.err); got != tt.want {
+				t.Errorf("IsTemporary(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func statsEqual(a, b *Stats) bool {
+	return a.Datetime.Equal(b.Datetime) &&
+		a.Views == b.Views &&
+		a.Clicks == b.Clicks &&
+		a.Actions == b.Actions &&
+		a.Spend.Equal(b.Spend) &&
+		a.CPM.Equal(b.CPM)
+}
@@ -0,0 +1,96 @@
+package tgads
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LimiterStats - счётчики ограничителя запросов с момента создания клиента
+type LimiterStats struct {
+	Requests  int64
+	Throttled int64
+	// Waited - суммарное время ожидания запросов
+	Waited time.Duration
+	// LastWait - ожидание последнего задержанного запроса
+	LastWait time.Duration
+}
+
+// limiter - token bucket, общий для всех запросов клиента.
+// Токены пополняются со скоростью rps, в запасе не больше burst
+type limiter struct {
+	mu     sync.Mutex
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  LimiterStats
+}
+
+func newLimiter(rps float64, burst int) *limiter {
+	return &limiter{
+		rps:    rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait забирает токен, при необходимости дожидаясь его пополнения
+func (l *limiter) wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve забирает токен заранее и возвращает, сколько нужно ждать до его появления
+func (l *limiter) reserve() (delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rps)
+	l.last = now
+	l.tokens--
+	l.stats.Requests++
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	delay = time.Duration(-l.tokens / l.rps * float64(time.Second))
+
+	l.stats.Throttled++
+	l.stats.Waited += delay
+	l.stats.LastWait = delay
+
+	return delay
+}
+
+// cancel возвращает токен запроса, который не дождался своей очереди
+func (l *limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+func (l *limiter) snapshot() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
@@ -0,0 +1,178 @@
+package tgads
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/icrowley/fake"
+)
+
+// Режимы смены прокси
+const (
+	// ProxyRotationRequest - каждая попытка запроса идёт через следующий прокси
+	ProxyRotationRequest = "request"
+	// ProxyRotationCampaign - все запросы одной РК идут через один прокси, см. WithProxySession
+	ProxyRotationCampaign = "campaign"
+)
+
+const defaultProxyCooldown = 10 * time.Minute
+
+type proxy struct {
+	url       *url.URL
+	transport *http.Transport
+	// userAgent меняется вместе с прокси и обновляется после каждой ошибки
+	userAgent      string
+	unhealthyUntil time.Time
+}
+
+// proxyPool выдаёт прокси по кругу, пропуская те, что недавно отвечали ошибкой
+type proxyPool struct {
+	mu       sync.Mutex
+	rotation string
+	cooldown time.Duration
+	list     []*proxy
+	next     int
+}
+
+func newProxyPool(addrs []string, rotation string, cooldown time.Duration) (res *proxyPool, err error) {
+	switch rotation {
+	case "":
+		rotation = ProxyRotationRequest
+	case ProxyRotationRequest, ProxyRotationCampaign:
+	default:
+		return res, fmt.Errorf("unknown proxy rotation %q", rotation)
+	}
+
+	if cooldown <= 0 {
+		cooldown = defaultProxyCooldown
+	}
+
+	res = &proxyPool{
+		rotation: rotation,
+		cooldown: cooldown,
+		list:     make([]*proxy, 0, len(addrs)),
+	}
+
+	for _, addr := range addrs {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return res, err
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return res, fmt.Errorf("unsupported proxy scheme %q in %s", u.Scheme, u.Redacted())
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(u)
+
+		res.list = append(res.list, &proxy{
+			url:       u,
+			transport: transport,
+			userAgent: fake.UserAgent(),
+		})
+	}
+
+	return res, nil
+}
+
+// pick возвращает прокси для попытки запроса. В режиме ProxyRotationCampaign
+// прокси сессии меняется, только если он стал нерабочим.
+// Если нерабочие все прокси, выбирается тот, у которого cooldown закончится раньше
+func (p *proxyPool) pick(ctx context.Context) (res *proxy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	s, _ := ctx.Value(proxySessionKey{}).(*proxySession)
+	if p.rotation == ProxyRotationCampaign && s != nil && s.proxy != nil && now.After(s.proxy.unhealthyUntil) {
+		return s.proxy
+	}
+
+	for range p.list {
+		item := p.list[p.next]
+		p.next = (p.next + 1) % len(p.list)
+
+		if now.After(item.unhealthyUntil) {
+			res = item
+			break
+		}
+
+		if res == nil || item.unhealthyUntil.Before(res.unhealthyUntil) {
+			res = item
+		}
+	}
+
+	if res.unhealthyUntil.After(now) {
+		log.Printf("tgads all proxies are unhealthy, using %s with cooldown until %s", res.url.Redacted(), res.unhealthyUntil.Format(time.RFC3339))
+	}
+
+	if s != nil {
+		s.proxy = res
+	}
+
+	return res
+}
+
+// fail исключает прокси из ротации на cooldown и выдаёт ему новый User-Agent
+func (p *proxyPool) fail(item *proxy, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	item.unhealthyUntil = time.Now().Add(p.cooldown)
+	item.userAgent = fake.UserAgent()
+
+	log.Printf("tgads proxy %s is unhealthy for %s: %s", item.url.Redacted(), p.cooldown, reason)
+}
+
+func (p *proxyPool) userAgent(item *proxy) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return item.userAgent
+}
+
+// RoundTrip отправляет запрос через прокси из пула, подставляя его User-Agent.
+// Сетевые ошибки и ответы 403, 407, 429 делают прокси нерабочим
+func (p *proxyPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	item := p.pick(req.Context())
+
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", p.userAgent(item))
+
+	resp, err := item.transport.RoundTrip(req)
+	if err != nil {
+		if req.Context().Err() == nil {
+			p.fail(item, err.Error())
+		}
+
+		return resp, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusProxyAuthRequired, http.StatusTooManyRequests:
+		p.fail(item, resp.Status)
+	}
+
+	return resp, nil
+}
+
+type proxySessionKey struct{}
+
+type proxySession struct {
+	proxy *proxy
+}
+
+// WithProxySession закрепляет за запросами с этим контекстом один прокси,
+// если клиент настроен на смену прокси по РК. Иначе ни на что не влияет
+func WithProxySession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, proxySessionKey{}, &proxySession{})
+}
@@ -0,0 +1,23 @@
+package tgads
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProxyPool_PickAllUnhealthy(t *testing.T) {
+	p, err := newProxyPool([]string{"http://proxy1:8080", "http://proxy2:8080"}, ProxyRotationRequest, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.fail(p.list[0], "test")
+	time.Sleep(time.Millisecond)
+	p.fail(p.list[1], "test")
+
+	got := p.pick(context.Background())
+	if got != p.list[0] {
+		t.Errorf("pick() = %s, want %s with the earliest cooldown end", got.url, p.list[0].url)
+	}
+}
@@ -0,0 +1,116 @@
+package tgads
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/shopspring/decimal"
+)
+
+// Подписи блоков настроек на странице РК в нижнем регистре
+const (
+	labelTitle       = "title"
+	labelCPM         = "cpm"
+	labelBudget      = "budget"
+	labelDailyBudget = "daily budget"
+	labelChannels    = "channels"
+	labelLanguages   = "languages"
+	labelTopics      = "topics"
+	labelCreated     = "created"
+)
+
+// Форматы даты создания РК
+var createdDatetimeFormats = []string{
+	"02 Jan 2006 15:04",
+	"02 Jan 2006",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+}
+
+var amountRegex = regexp.MustCompile(`\d[\d\s,]*(?:\.\d+)?`)
+
+// parseSettings заполняет настройки РК из блоков «подпись — значение».
+// Блоки без значения на странице пропускаются: например, у РК без таргетинга по темам нет блока Topics.
+// Настройки необязательны, поэтому значение, которое не удалось разобрать, пишется в лог и остаётся пустым
+func parseSettings(doc *goquery.Document, res *Campaign) {
+	values := make(map[string]*goquery.Selection)
+
+	doc.Find("div.pr-ad-info-label").Each(func(_ int, s *goquery.Selection) {
+		value := s.NextFiltered("div.pr-ad-info-value")
+		if value.Length() > 0 {
+			values[strings.ToLower(strings.TrimSpace(s.Text()))] = value
+		}
+	})
+
+	if v, ok := values[labelTitle]; ok {
+		res.Title = strings.TrimSpace(v.Text())
+	}
+
+	res.CPMBid = parseAmount(res.Id, values, labelCPM)
+	res.Budget = parseAmount(res.Id, values, labelBudget)
+	res.DailyBudget = parseAmount(res.Id, values, labelDailyBudget)
+
+	res.Channels = parseList(values[labelChannels])
+	res.Languages = parseList(values[labelLanguages])
+	res.Topics = parseList(values[labelTopics])
+
+	if v, ok := values[labelCreated]; ok {
+		res.CreatedAt = parseCreated(res.Id, strings.TrimSpace(v.Text()))
+	}
+}
+
+// parseAmount разбирает сумму вида "1,250.50 TON", см. parseDecimal.
+// Если блока нет или в нём не сумма (например, "Unlimited"), возвращает nil
+func parseAmount(campaignId string, values map[string]*goquery.Selection, label string) (res *decimal.Decimal) {
+	v, ok := values[label]
+	if !ok {
+		return nil
+	}
+
+	amount, err := parseDecimal(amountRegex.FindString(v.Text()))
+	if err != nil {
+		log.Printf("tgads campaign %s: cant parse %s %q", campaignId, label, strings.TrimSpace(v.Text()))
+		return nil
+	}
+
+	return &amount
+}
+
+// parseList возвращает ссылки блока, а если их нет - значения через запятую
+func parseList(v *goquery.Selection) (res []string) {
+	res = make([]string, 0)
+
+	if v == nil {
+		return res
+	}
+
+	items := v.Find("a").Map(func(_ int, s *goquery.Selection) string { return s.Text() })
+	if len(items) == 0 {
+		items = strings.Split(v.Text(), ",")
+	}
+
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+func parseCreated(campaignId, s string) (res *time.Time) {
+	for _, format := range createdDatetimeFormats {
+		t, err := time.Parse(format, s)
+		if err == nil {
+			return &t
+		}
+	}
+
+	log.Printf("tgads campaign %s: cant parse %s %q", campaignId, labelCreated, s)
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package tgads
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Таблицы, которые отдаёт ads.telegram.org
+const (
+	tableStats  = "stats"
+	tableBudget = "budget"
+)
+
+// Колонки таблиц
+const (
+	columnDate    = "date"
+	columnViews   = "views"
+	columnClicks  = "clicks"
+	columnActions = "actions"
+	columnSpend   = "spend"
+)
+
+// columnNames - известные названия колонок после normalizeColumn
+var columnNames = map[string][]string{
+	columnDate:    {"date", "datetime", "time"},
+	columnViews:   {"views", "impressions"},
+	columnClicks:  {"clicks"},
+	columnActions: {"actions", "joins"},
+	columnSpend:   {"spent", "spend", "spent budget", "amount spent"},
+}
+
+// currencySuffixRegex - валюта в конце заголовка: "Spent, TON", "Spent (TON)"
+var currencySuffixRegex = regexp.MustCompile(`\s*(?:,\s*ton|\(ton\))$`)
+
+// MissingColumnError - в таблице нет обязательной колонки
+type MissingColumnError struct {
+	Table  string
+	Column string
+	Header []string
+}
+
+func (e *MissingColumnError) Error() string {
+	return fmt.Sprintf("no %s column, header %q", e.Column, e.Header)
+}
+
+func (e *MissingColumnError) Unwrap() error {
+	return ErrLayoutChanged
+}
+
+// mapColumns находит номера колонок по заголовку таблицы. Лишние колонки и их порядок не важны.
+// Необязательные колонки, которых нет в заголовке, в результат не попадают
+func mapColumns(table string, header []string, required, optional []string) (res map[string]int, err error) {
+	res = make(map[string]int)
+	names := append(append([]string(nil), required...), optional...)
+
+	for i, title := range header {
+		title = normalizeColumn(title)
+
+		for _, name := range names {
+			if _, ok := res[name]; ok || !matchesColumn(title, name) {
+				continue
+			}
+
+			res[name] = i
+
+			break
+		}
+	}
+
+	for _, name := range required {
+		if _, ok := res[name]; !ok {
+			return res, &MissingColumnError{Table: table, Column: name, Header: header}
+		}
+	}
+
+	return res, nil
+}
+
+// normalizeColumn приводит заголовок колонки к нижнему регистру и убирает BOM, лишние пробелы и валюту
+func normalizeColumn(title string) string {
+	title = strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(title, "\ufeff")), " "))
+
+	return currencySuffixRegex.ReplaceAllString(title, "")
+}
+
+func matchesColumn(title, name string) bool {
+	return slices.Contains(columnNames[name], title)
+}
+
+// parseStatsTable добавляет в byDatetime просмотры, клики и действия из таблицы статистики
+func parseStatsTable(rows [][]string, datetimeFormat string, byDatetime map[int64]*Stats) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns, err := mapColumns(tableStats, rows[0], []string{columnDate, columnViews}, []string{columnClicks, columnActions})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows[1:] {
+		item, err := statsItem(row[columns[columnDate]], datetimeFormat, byDatetime)
+		if err != nil {
+			return err
+		}
+
+		item.Views, err = intCell(row, columns, columnViews)
+		if err != nil {
+			return err
+		}
+
+		item.Clicks, err = intCell(row, columns, columnClicks)
+		if err != nil {
+			return err
+		}
+
+		item.Actions, err = intCell(row, columns, columnActions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseBudgetTable добавляет в byDatetime расход из таблицы бюджета
+func parseBudgetTable(rows [][]string, datetimeFormat string, byDatetime map[int64]*Stats) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns, err := mapColumns(tableBudget, rows[0], []string{columnDate, columnSpend}, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows[1:] {
+		item, err := statsItem(row[columns[columnDate]], datetimeFormat, byDatetime)
+		if err != nil {
+			return err
+		}
+
+		spend := strings.TrimSpace(row[columns[columnSpend]])
+		if spend == "" {
+			continue
+		}
+
+		item.Spend, err = parseDecimal(spend)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// statsItem возвращает строку статистики за дату, создавая её при необходимости.
+// Ключ - unix-время: time.Time с разными *Location не равны как ключи map
+func statsItem(cell, datetimeFormat string, byDatetime map[int64]*Stats) (res *Stats, err error) {
+	datetime, err := parseDatetime(datetimeFormat, strings.TrimSpace(cell))
+	if err != nil {
+		return res, err
+	}
+
+	res, ok := byDatetime[datetime.Unix()]
+	if !ok {
+		res = &Stats{Datetime: datetime}
+		byDatetime[datetime.Unix()] = res
+	}
+
+	return res, nil
+}
+
+// datetimeZones - часовые пояса, которые могут быть в почасовых таблицах
+var datetimeZones = map[string]*time.Location{
+	"UTC": time.UTC,
+	"GMT": time.UTC,
+	"MSK": time.FixedZone("MSK", 3*60*60),
+}
+
+// parseDatetime разбирает дату из таблицы. Дата без пояса считается в UTC.
+// Незнакомый пояс time.Parse молча считает нулевым смещением, поэтому такие даты отклоняются
+func parseDatetime(format, s string) (res time.Time, err error) {
+	res, err = time.ParseInLocation(format, s, time.UTC)
+	if err != nil {
+		return res, err
+	}
+
+	zone, _ := res.Zone()
+
+	loc, ok := datetimeZones[zone]
+	if !ok {
+		return res, fmt.Errorf("unknown time zone %q in %q", zone, s)
+	}
+
+	return time.ParseInLocation(format, s, loc)
+}
+
+// intCell разбирает целое число из колонки name. Если колонки нет, возвращает 0
+func intCell(row []string, columns map[string]int, name string) (res int, err error) {
+	i, ok := columns[name]
+	if !ok {
+		return 0, nil
+	}
+
+	value := onlyNumeric(row[i])
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func onlyNumeric(in string) (out string) {
+	for _, v := range in {
+		if v >= '0' && v <= '9' {
+			out += string(v)
+		}
+	}
+
+	return out
+}